@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // timezones for stats in images without zoneinfo
 
 	"github.com/blankspace9/notes-app/internal/app"
 	"github.com/blankspace9/notes-app/internal/config"
@@ -26,6 +27,7 @@ func main() {
 	application := app.New(log, cfg)
 
 	go application.HTTPServer.Run()
+	application.Workers.Run()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
@@ -35,6 +37,8 @@ func main() {
 	log.Info("stopping application", slog.String("signal", sign.String()))
 
 	application.HTTPServer.Stop()
+	application.Workers.Stop()
+	application.Notifications.Stop()
 
 	log.Info("application stopped")
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/config"
+	"github.com/blankspace9/notes-app/internal/lib/keyring"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+// Rotates encryption keys while the server keeps running. Deploy the new master key
+// (a higher version in the key file, keeping the old one) first, then run:
+//
+//	rekey                    - rewrap all data keys with the newest master key
+//	rekey --reencrypt-notes  - also issue new data keys and re-encrypt every note
+//
+// After it finishes the old master key can be removed from the key file.
+func main() {
+	var batchSize int
+
+	flag.IntVar(&batchSize, "batch-size", 100, "rows per transaction")
+	reencrypt := flag.Bool("reencrypt-notes", false, "re-encrypt notes with new data keys")
+	flag.Parse()
+
+	storageCfg, encryptionCfg := config.RekeyMustLoad()
+
+	kr, err := keyring.Load(encryptionCfg.MasterKey, encryptionCfg.MasterKeyVersion, encryptionCfg.KeyFile)
+	if err != nil {
+		panic(err)
+	}
+
+	s, err := storage.New(storage.PostgresConnectionInfo(*storageCfg), storage.WithKeyring(kr))
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+
+	n, err := s.RewrapDataKeys(ctx, batchSize)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("rewrapped %d data keys with master key version %d\n", n, kr.Current())
+
+	if *reencrypt {
+		n, err = s.ReencryptNotes(ctx, batchSize)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("re-encrypted %d notes\n", n)
+	}
+}
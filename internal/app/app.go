@@ -1,37 +1,159 @@
 package app
 
 import (
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/blankspace9/notes-app/internal/app/httpapp"
+	"github.com/blankspace9/notes-app/internal/app/workerapp"
 	"github.com/blankspace9/notes-app/internal/config"
 	"github.com/blankspace9/notes-app/internal/delivery/rest"
 	"github.com/blankspace9/notes-app/internal/external/spellchecker"
+	"github.com/blankspace9/notes-app/internal/lib/keyring"
 	"github.com/blankspace9/notes-app/internal/services/authservice"
 	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+	"github.com/blankspace9/notes-app/internal/services/notificationservice"
 	"github.com/blankspace9/notes-app/internal/storage"
 )
 
+const (
+	// termsBackfillInterval is the pause of the term backfill once every note is indexed
+	termsBackfillInterval = time.Minute
+
+	// fingerprintsBackfillInterval is the pause of the fingerprint backfill once every note has one
+	fingerprintsBackfillInterval = time.Minute
+
+	ruleJobsPollInterval = time.Second
+)
+
 type App struct {
-	HTTPServer *httpapp.App
+	HTTPServer    *httpapp.App
+	Workers       *workerapp.App
+	Notifications *notificationservice.NotificationService
 }
 
 func New(log *slog.Logger, cfg *config.Config) *App {
-	storage, err := storage.New(storage.PostgresConnectionInfo(cfg.Storage))
+	opts := []storage.Option{storage.WithLimits(cfg.Limits.MaxNotesPerUser, cfg.Limits.MaxTotalBytes)}
+	if cfg.Encryption.Enabled {
+		kr, err := keyring.Load(cfg.Encryption.MasterKey, cfg.Encryption.MasterKeyVersion, cfg.Encryption.KeyFile)
+		if err != nil {
+			panic(err)
+		}
+
+		opts = append(opts, storage.WithKeyring(kr))
+	}
+
+	storage, err := storage.New(storage.PostgresConnectionInfo(cfg.Storage), opts...)
 	if err != nil {
 		panic(err)
 	}
 
 	authService := authservice.New(log, storage, storage, cfg.JWT)
 
-	spellChecker := spellchecker.New(cfg.SpellChecker.URL)
-	notesService := noteservice.New(log, storage, spellChecker)
+	channels := []notificationservice.Channel{
+		notificationservice.NewInAppChannel(storage),
+		notificationservice.NewWebhookChannel(cfg.Notifications.WebhookTimeout),
+	}
+	if smtp := cfg.Notifications.SMTP; smtp.Host != "" {
+		channels = append(channels, notificationservice.NewEmailChannel(smtp.Host, smtp.Port, smtp.From, smtp.Username, smtp.Password))
+	}
+	notificationService := notificationservice.New(log, storage, storage, cfg.Notifications.MaxDeliveries, channels...)
+
+	spellChecker, err := newSpellChecker(cfg.SpellChecker)
+	if err != nil {
+		panic(err)
+	}
 
-	handler := rest.New(log, authService, notesService)
+	managers := noteservice.Managers{
+		Notes:          storage,
+		Rules:          storage,
+		Fields:         storage,
+		Activity:       storage,
+		Shares:         storage,
+		Comments:       storage,
+		SmartFolders:   storage,
+		Settings:       storage,
+		Revisions:      storage,
+		Dictionary:     storage,
+		Spelling:       storage,
+		SpellcheckJobs: storage,
+	}
+
+	notesService := noteservice.New(log, managers, notificationService, spellChecker,
+		cfg.Limits, cfg.Stats, cfg.Daily, spellcheckConfig(log, cfg))
+
+	handler := rest.New(log, authService, notesService, notificationService, cfg.Admin.UserIDs)
 
 	httpApp := httpapp.New(log, handler.InitRouter(), cfg.HTTPServer.Port, cfg.HTTPServer.Timeout)
 
+	workers := workerapp.New(log)
+	// without async spellchecks nothing is queued
+	if cfg.SpellChecker.Async {
+		workers.Add("spellcheck", notesService.ProcessSpellcheckJob, cfg.SpellChecker.Workers, cfg.SpellChecker.PollInterval)
+	}
+	workers.Add("rule jobs", notesService.ProcessRuleJob, 1, ruleJobsPollInterval)
+	workers.Add("terms backfill", notesService.BackfillTerms, 1, termsBackfillInterval)
+	workers.Add("fingerprints backfill", notesService.BackfillFingerprints, 1, fingerprintsBackfillInterval)
+
 	return &App{
-		HTTPServer: httpApp,
+		HTTPServer:    httpApp,
+		Workers:       workers,
+		Notifications: notificationService,
 	}
 }
+
+// newSpellChecker creates the backend wrapped with the cache of paragraphs, cache size 0 disables the cache
+func newSpellChecker(cfg config.SpellChecker) (noteservice.SpellChecker, error) {
+	backend, err := newSpellCheckerBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.CacheSize <= 0 || cfg.Backend == "none" {
+		return backend, nil
+	}
+
+	variant := strings.Join([]string{cfg.Backend, cfg.URL, strings.Join(cfg.Languages, ",")}, "|")
+
+	return spellchecker.NewCached(backend, cfg.CacheSize, cfg.CacheTTL, variant), nil
+}
+
+func newSpellCheckerBackend(cfg config.SpellChecker) (noteservice.SpellChecker, error) {
+	switch cfg.Backend {
+	case "yandex":
+		return spellchecker.New(cfg.URL,
+			spellchecker.WithTimeouts(cfg.ConnectTimeout, cfg.Timeout),
+			spellchecker.WithRetries(cfg.Retries, cfg.RetryBackoff),
+			spellchecker.WithCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		), nil
+	case "hunspell":
+		return spellchecker.NewHunspell(cfg.HunspellDir, cfg.Languages)
+	case "none":
+		return spellchecker.NewNoop(), nil
+	default:
+		return nil, fmt.Errorf("unknown spellchecker backend %q", cfg.Backend)
+	}
+}
+
+// spellcheckConfig limits the spellcheck budget, so at least half of the server timeout is left to saving the note.
+// Without a budget the spellcheck gets half of the server timeout.
+func spellcheckConfig(log *slog.Logger, cfg *config.Config) config.SpellChecker {
+	spellcheck := cfg.SpellChecker
+	if timeout := cfg.HTTPServer.Timeout; timeout > 0 {
+		if spellcheck.Budget <= 0 {
+			spellcheck.Budget = timeout / 2
+		}
+
+		spellcheck.Budget = min(spellcheck.Budget, timeout/2)
+	}
+
+	if spellcheck.Budget > 0 {
+		log.Info("spellcheck budget is set", slog.Duration("budget", spellcheck.Budget))
+	} else {
+		log.Warn("spellcheck budget is unlimited")
+	}
+
+	return spellcheck
+}
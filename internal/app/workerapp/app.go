@@ -0,0 +1,113 @@
+package workerapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+)
+
+// Task runs the next due job, it returns false when no job is due
+type Task func(ctx context.Context) (processed bool, err error)
+
+type task struct {
+	name         string
+	run          Task
+	workers      int
+	pollInterval time.Duration
+}
+
+// App is a pool of workers processing background jobs
+type App struct {
+	log   *slog.Logger
+	tasks []task
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New(log *slog.Logger) *App {
+	return &App{
+		log:  log,
+		stop: make(chan struct{}),
+	}
+}
+
+// Add runs the task on the number of workers, every worker waits for the poll interval when no job is due.
+// Tasks are added before Run.
+func (a *App) Add(name string, run Task, workers int, pollInterval time.Duration) {
+	a.tasks = append(a.tasks, task{name: name, run: run, workers: workers, pollInterval: pollInterval})
+}
+
+func (a *App) Run() {
+	const op = "workerapp.Run"
+
+	log := a.log.With(slog.String("op", op))
+
+	for _, t := range a.tasks {
+		for i := 0; i < t.workers; i++ {
+			a.wg.Add(1)
+			go a.work(t)
+		}
+
+		log.Info("workers are running", slog.String("task", t.name), slog.Int("workers", t.workers))
+	}
+}
+
+// Stop waits for running jobs, they are limited by the job timeouts
+func (a *App) Stop() {
+	const op = "workerapp.Stop"
+
+	a.log.With(slog.String("op", op)).Info("stopping workers")
+
+	close(a.stop)
+	a.wg.Wait()
+}
+
+// work runs jobs one after another and waits for the poll interval when no job is due.
+// Jobs are not cancelled on stop, so a stopped worker does not count them as failed.
+func (a *App) work(t task) {
+	defer a.wg.Done()
+
+	log := a.log.With(slog.String("task", t.name))
+
+	for {
+		select {
+		case <-a.stop:
+			return
+		default:
+		}
+
+		processed, err := a.run(t)
+		if err != nil {
+			log.Error("failed to process job", sl.Err(err))
+		}
+
+		if processed && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(t.pollInterval)
+		select {
+		case <-a.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// run processes a job of the task, a panic is logged as an error and the job is left to expire its lease
+func (a *App) run(t task) (processed bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return t.run(context.Background())
+}
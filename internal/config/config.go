@@ -11,11 +11,17 @@ import (
 
 type (
 	Config struct {
-		Env          string     `yaml:"env" env-default:"local"`
-		HTTPServer   HTTPServer `yaml:"http"`
-		JWT          JWT        `yaml:"tokens"`
-		Storage      Postgres
-		SpellChecker SpellChecker
+		Env           string        `yaml:"env" env-default:"local"`
+		HTTPServer    HTTPServer    `yaml:"http"`
+		JWT           JWT           `yaml:"tokens"`
+		Limits        Limits        `yaml:"limits"`
+		Stats         Stats         `yaml:"stats"`
+		Daily         Daily         `yaml:"daily"`
+		Notifications Notifications `yaml:"notifications"`
+		Storage       Postgres
+		Encryption    Encryption
+		SpellChecker  SpellChecker
+		Admin         Admin
 	}
 
 	HTTPServer struct {
@@ -29,6 +35,37 @@ type (
 		RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env-required:"true"`
 	}
 
+	// Limits of a single user, 0 means unlimited
+	Limits struct {
+		MaxNoteLength   int   `yaml:"max_note_length" env-default:"10000"`
+		MaxNotesPerUser int64 `yaml:"max_notes_per_user" env-default:"0"`
+		MaxTotalBytes   int64 `yaml:"max_total_bytes" env-default:"0"`
+	}
+
+	Stats struct {
+		Timezone string `yaml:"timezone" env-default:"UTC"`
+	}
+
+	// Daily is the default template of journal notes, see the placeholders models.DailyDate and models.DailyWeekday
+	Daily struct {
+		Template string `yaml:"template" env-default:"{{.Date}}"`
+	}
+
+	Notifications struct {
+		WebhookTimeout time.Duration `yaml:"webhook_timeout" env-default:"5s"`
+		MaxDeliveries  int           `yaml:"max_deliveries" env-default:"16"`
+		SMTP           SMTP
+	}
+
+	// SMTP server for email notifications, the email channel is disabled without a host
+	SMTP struct {
+		Host     string `env:"SMTP_HOST"`
+		Port     string `env:"SMTP_PORT" env-default:"1025"`
+		From     string `env:"SMTP_FROM" env-default:"notes@localhost"`
+		Username string `env:"SMTP_USERNAME"`
+		Password string `env:"SMTP_PASSWORD"`
+	}
+
 	Postgres struct {
 		Host     string `env:"POSTGRES_HOST" env-default:"localhost"`
 		Port     string `env:"POSTGRES_PORT" env-default:"5432"`
@@ -38,8 +75,50 @@ type (
 		Password string `env:"POSTGRES_PASSWORD"`
 	}
 
+	// Encryption of note bodies at rest. Master keys are versioned: the key file
+	// contains lines "<version>:<base64 key>", the highest version wraps new data keys.
+	Encryption struct {
+		Enabled          bool   `env:"ENCRYPTION_ENABLED" env-default:"false"`
+		MasterKey        string `env:"ENCRYPTION_MASTER_KEY"`
+		MasterKeyVersion int    `env:"ENCRYPTION_MASTER_KEY_VERSION" env-default:"1"`
+		KeyFile          string `env:"ENCRYPTION_KEY_FILE"`
+	}
+
+	// SpellChecker backend is yandex, hunspell or none. Hunspell loads
+	// <HunspellDir>/<language>.aff and .dic for every language.
+	// Timeouts, retries and the circuit breaker apply to the yandex backend.
+	// Budget limits spellchecking of a note including retries.
+	// Results of paragraphs are cached for any backend, cache size 0 disables the cache.
+	// RateLimit is the number of standalone checks per minute of a user, 0 means unlimited.
+	// Async saves notes without waiting for the spellchecker, Workers check them in background.
+	// A job is retried JobAttempts times with exponential JobBackoff, then it is dead-lettered.
 	SpellChecker struct {
-		URL string `env:"SPELL_CHECKER_URL"`
+		Backend          string        `env:"SPELL_CHECKER_BACKEND" env-default:"yandex"`
+		URL              string        `env:"SPELL_CHECKER_URL"`
+		Budget           time.Duration `env:"SPELL_CHECKER_BUDGET" env-default:"5s"`
+		ConnectTimeout   time.Duration `env:"SPELL_CHECKER_CONNECT_TIMEOUT" env-default:"1s"`
+		Timeout          time.Duration `env:"SPELL_CHECKER_TIMEOUT" env-default:"3s"`
+		Retries          int           `env:"SPELL_CHECKER_RETRIES" env-default:"2"`
+		RetryBackoff     time.Duration `env:"SPELL_CHECKER_RETRY_BACKOFF" env-default:"200ms"`
+		BreakerThreshold int           `env:"SPELL_CHECKER_BREAKER_THRESHOLD" env-default:"5"`
+		BreakerCooldown  time.Duration `env:"SPELL_CHECKER_BREAKER_COOLDOWN" env-default:"30s"`
+		RateLimit        int           `env:"SPELL_CHECKER_RATE_LIMIT" env-default:"60"`
+		RateBurst        int           `env:"SPELL_CHECKER_RATE_BURST" env-default:"10"`
+		CacheSize        int           `env:"SPELL_CHECKER_CACHE_SIZE" env-default:"10000"`
+		CacheTTL         time.Duration `env:"SPELL_CHECKER_CACHE_TTL" env-default:"24h"`
+		HunspellDir      string        `env:"SPELL_CHECKER_HUNSPELL_DIR" env-default:"./dictionaries"`
+		Languages        []string      `env:"SPELL_CHECKER_LANGUAGES" env-separator:"," env-default:"ru_RU,en_US"`
+		Async            bool          `env:"SPELL_CHECKER_ASYNC" env-default:"false"`
+		Workers          int           `env:"SPELL_CHECKER_WORKERS" env-default:"2"`
+		PollInterval     time.Duration `env:"SPELL_CHECKER_POLL_INTERVAL" env-default:"1s"`
+		JobTimeout       time.Duration `env:"SPELL_CHECKER_JOB_TIMEOUT" env-default:"30s"`
+		JobAttempts      int           `env:"SPELL_CHECKER_JOB_ATTEMPTS" env-default:"5"`
+		JobBackoff       time.Duration `env:"SPELL_CHECKER_JOB_BACKOFF" env-default:"10s"`
+	}
+
+	// Admin users can see the spellcheck job queue
+	Admin struct {
+		UserIDs []int64 `env:"ADMIN_USER_IDS" env-separator:","`
 	}
 )
 
@@ -78,10 +157,18 @@ func MustLoadByPath(configPath string, envPath string) *Config {
 		panic("failed to read config: " + err.Error())
 	}
 
+	if err := cleanenv.ReadEnv(&cfg.Encryption); err != nil {
+		panic("failed to read config: " + err.Error())
+	}
+
 	if err := cleanenv.ReadEnv(&cfg.SpellChecker); err != nil {
 		panic("failed to read config: " + err.Error())
 	}
 
+	if err := cleanenv.ReadEnv(&cfg.Notifications.SMTP); err != nil {
+		panic("failed to read config: " + err.Error())
+	}
+
 	if err := cleanenv.ReadEnv(&cfg.JWT); err != nil {
 		panic("failed to read config: " + err.Error())
 	}
@@ -105,6 +192,27 @@ func MigrateMustLoad() *Postgres {
 	return cfg
 }
 
+func RekeyMustLoad() (*Postgres, *Encryption) {
+	storage := new(Postgres)
+	encryption := new(Encryption)
+
+	if os.Getenv("DOCKER_ENV") != "true" {
+		if err := godotenv.Load(".env"); err != nil {
+			panic("failed to load .env file: " + err.Error())
+		}
+	}
+
+	if err := cleanenv.ReadEnv(storage); err != nil {
+		panic("failed to read config: " + err.Error())
+	}
+
+	if err := cleanenv.ReadEnv(encryption); err != nil {
+		panic("failed to read config: " + err.Error())
+	}
+
+	return storage, encryption
+}
+
 // flag > env > default
 func fetchConfigPath() string {
 	var res string
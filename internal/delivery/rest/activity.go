@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) deleteNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.DeleteNote(r.Context(), userID, noteID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to delete note: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to delete note: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to delete note", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) restoreNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.RestoreNote(r.Context(), userID, noteID)
+	if err != nil {
+		switch {
+		case errors.Is(err, noteservice.ErrNoteNotFound):
+			http.Error(w, "Failed to restore note: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, noteservice.ErrDailyNoteExists):
+			http.Error(w, "Failed to restore note: "+noteservice.ErrDailyNoteExists.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "Failed to restore note: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to restore note", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getActivity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	activity, err := h.notesService.GetActivity(r.Context(), userID, noteID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to get activity: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to get activity: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get activity", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.NoteActivity{
+		"activity": activity,
+	})
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+// getSpellcheckQueue lists the latest jobs, ?status=dead shows dead-lettered ones
+func (h *Handler) getSpellcheckQueue(w http.ResponseWriter, r *http.Request) {
+	queue, err := h.notesService.GetSpellcheckQueue(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "Failed to get spellcheck queue: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get spellcheck queue", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, queue)
+}
+
+func (h *Handler) retrySpellcheckJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid job id", http.StatusBadRequest)
+		h.log.Warn("invalid job id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.RetrySpellcheckJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrSpellcheckJobNotFound) {
+			http.Error(w, "Failed to retry spellcheck job: "+noteservice.ErrSpellcheckJobNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to retry spellcheck job: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to retry spellcheck job", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,175 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+)
+
+func (h *Handler) addComment(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	var req models.CommentRequest
+
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&req)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid comment: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid comment", sl.Err(err))
+		return
+	}
+
+	comment, err := h.notesService.AddComment(r.Context(), userID, noteID, req)
+	if err != nil {
+		h.writeAccessError(w, "Failed to add comment: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, comment)
+}
+
+func (h *Handler) getComments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	comments, err := h.notesService.GetComments(r.Context(), userID, noteID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to get comments: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.Comment{
+		"comments": comments,
+	})
+}
+
+func (h *Handler) updateComment(w http.ResponseWriter, r *http.Request) {
+	userID, noteID, commentID, ok := h.commentPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CommentUpdateRequest
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&req)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid comment: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid comment", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.UpdateComment(r.Context(), userID, noteID, commentID, req.Body)
+	if err != nil {
+		h.writeAccessError(w, "Failed to update comment: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteComment(w http.ResponseWriter, r *http.Request) {
+	userID, noteID, commentID, ok := h.commentPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.notesService.DeleteComment(r.Context(), userID, noteID, commentID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to delete comment: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) resolveComment(w http.ResponseWriter, r *http.Request) {
+	h.setCommentResolved(w, r, true)
+}
+
+func (h *Handler) unresolveComment(w http.ResponseWriter, r *http.Request) {
+	h.setCommentResolved(w, r, false)
+}
+
+func (h *Handler) setCommentResolved(w http.ResponseWriter, r *http.Request, resolved bool) {
+	userID, noteID, commentID, ok := h.commentPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.notesService.ResolveComment(r.Context(), userID, noteID, commentID, resolved)
+	if err != nil {
+		h.writeAccessError(w, "Failed to resolve comment: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// commentPath retrieves the user, note and comment ids of a comment request
+func (h *Handler) commentPath(w http.ResponseWriter, r *http.Request) (userID, noteID, commentID int64, ok bool) {
+	userID, ok = r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return 0, 0, 0, false
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return 0, 0, 0, false
+	}
+
+	commentID, err = pathID(r, "commentId")
+	if err != nil {
+		http.Error(w, "Invalid comment id", http.StatusBadRequest)
+		h.log.Warn("invalid comment id", sl.Err(err))
+		return 0, 0, 0, false
+	}
+
+	return userID, noteID, commentID, true
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) getDailyNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	daily, err := h.notesService.GetDailyNote(r.Context(), userID, mux.Vars(r)["date"])
+	if err != nil {
+		if h.writeQuotaError(w, err) || h.writeFieldError(w, err) {
+			h.log.Warn("failed to get daily note", sl.Err(err))
+			return
+		}
+
+		if errors.Is(err, noteservice.ErrInvalidDate) {
+			http.Error(w, "Failed to get daily note: "+noteservice.ErrInvalidDate.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to get daily note: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get daily note", sl.Err(err))
+		return
+	}
+
+	status := http.StatusOK
+	if daily.Created {
+		status = http.StatusCreated
+	}
+
+	h.writeJSON(w, status, daily)
+}
+
+func (h *Handler) getDailyCalendar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	calendar, err := h.notesService.GetDailyCalendar(r.Context(), userID, r.URL.Query().Get("month"))
+	if err != nil {
+		if errors.Is(err, noteservice.ErrInvalidDate) {
+			http.Error(w, "Failed to get daily calendar: "+noteservice.ErrInvalidDate.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to get daily calendar: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get daily calendar", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, calendar)
+}
@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+	"github.com/gorilla/mux"
+)
+
+// maxDictionaryImportSize caps an imported word list
+const maxDictionaryImportSize = 1 << 20
+
+func (h *Handler) getDictionary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	words, err := h.notesService.GetDictionary(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get dictionary: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get dictionary", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"words": words,
+	})
+}
+
+func (h *Handler) addDictionaryWords(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var req models.DictionaryRequest
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&req)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid words: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid words", sl.Err(err))
+		return
+	}
+
+	added, err := h.notesService.AddDictionaryWords(r.Context(), userID, req.Words)
+	if err != nil {
+		if h.writeQuotaError(w, err) {
+			h.log.Warn("failed to add words", sl.Err(err))
+			return
+		}
+
+		http.Error(w, "Failed to add words: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to add words", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"added": added,
+	})
+}
+
+func (h *Handler) deleteDictionaryWord(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	err := h.notesService.DeleteDictionaryWord(r.Context(), userID, mux.Vars(r)["word"])
+	if err != nil {
+		if errors.Is(err, noteservice.ErrWordNotFound) {
+			http.Error(w, "Failed to delete word: "+noteservice.ErrWordNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to delete word: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to delete word", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// exportDictionary responds with a plain word list, one word per line
+func (h *Handler) exportDictionary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	words, err := h.notesService.GetDictionary(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to export dictionary: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to export dictionary", sl.Err(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="dictionary.txt"`)
+	w.WriteHeader(http.StatusOK)
+	for _, word := range words {
+		io.WriteString(w, word+"\n")
+	}
+}
+
+// importDictionary adds words of a plain word list, words already in the dictionary are skipped
+func (h *Handler) importDictionary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var list strings.Builder
+	_, err := io.Copy(&list, http.MaxBytesReader(w, r.Body, maxDictionaryImportSize))
+	if err != nil {
+		http.Error(w, "Failed to read word list: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to read word list", sl.Err(err))
+		return
+	}
+
+	added, err := h.notesService.ImportDictionary(r.Context(), userID, list.String())
+	if err != nil {
+		if h.writeQuotaError(w, err) {
+			h.log.Warn("failed to import dictionary", sl.Err(err))
+			return
+		}
+
+		if errors.Is(err, noteservice.ErrInvalidWordList) {
+			http.Error(w, "Failed to import dictionary: "+err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to import dictionary: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to import dictionary", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"added": added,
+	})
+}
@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) createField(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var field models.FieldDefinition
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&field)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = field.Validate()
+	if err != nil {
+		http.Error(w, "Invalid field: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid field", sl.Err(err))
+		return
+	}
+
+	id, err := h.notesService.CreateField(r.Context(), userID, field)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrFieldExists) {
+			http.Error(w, "Failed to create field: "+noteservice.ErrFieldExists.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, "Failed to create field: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to create field", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]int64{
+		"id": id,
+	})
+}
+
+func (h *Handler) getFields(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	fields, err := h.notesService.GetFields(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get fields: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get fields", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.FieldDefinition{
+		"fields": fields,
+	})
+}
+
+func (h *Handler) deleteField(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	fieldID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid field id", http.StatusBadRequest)
+		h.log.Warn("invalid field id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.DeleteField(r.Context(), userID, fieldID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrFieldNotFound) {
+			http.Error(w, "Failed to delete field: "+noteservice.ErrFieldNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to delete field: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to delete field", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
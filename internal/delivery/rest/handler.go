@@ -2,8 +2,10 @@ package rest
 
 import (
 	"context"
+	"expvar"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/blankspace9/notes-app/internal/domain/models"
@@ -11,9 +13,12 @@ import (
 )
 
 type Handler struct {
-	log          *slog.Logger
-	authService  AuthService
-	notesService NotesService
+	log                  *slog.Logger
+	authService          AuthService
+	notesService         NotesService
+	notificationsService NotificationsService
+	// admins can see the spellcheck job queue and metrics of the process
+	admins map[int64]bool
 }
 
 type AuthService interface {
@@ -25,21 +30,93 @@ type AuthService interface {
 }
 
 type NotesService interface {
-	CreateNote(ctx context.Context, note string, userID int64) (noteID int64, spellingErrors []models.SpellError, err error)
-	GetNotes(ctx context.Context, userID int64, page, limit int) (notes []models.Note, err error)
+	CreateNote(ctx context.Context, note models.NoteRequest, userID int64) (models.SaveNoteResult, error)
+	UpdateNote(ctx context.Context, note models.NoteRequest, userID, noteID int64) (models.SaveNoteResult, error)
+	GetNote(ctx context.Context, userID, noteID int64) (models.Note, error)
+	DeleteNote(ctx context.Context, userID, noteID int64) error
+	RestoreNote(ctx context.Context, userID, noteID int64) error
+	GetActivity(ctx context.Context, userID, noteID int64) ([]models.NoteActivity, error)
+	ShareNote(ctx context.Context, userID, noteID int64, email string) error
+	UnshareNote(ctx context.Context, userID, noteID, recipientID int64) error
+	GetShares(ctx context.Context, userID, noteID int64) ([]models.NoteShare, error)
+	AddComment(ctx context.Context, userID, noteID int64, comment models.CommentRequest) (models.Comment, error)
+	GetComments(ctx context.Context, userID, noteID int64) ([]models.Comment, error)
+	UpdateComment(ctx context.Context, userID, noteID, commentID int64, body string) error
+	DeleteComment(ctx context.Context, userID, noteID, commentID int64) error
+	ResolveComment(ctx context.Context, userID, noteID, commentID int64, resolved bool) error
+	GetNotes(ctx context.Context, userID int64, filter models.NoteFilter) (notes []models.Note, err error)
+	SearchNotes(ctx context.Context, userID int64, query string, filter models.SearchFilter) ([]models.Note, error)
+	Suggest(ctx context.Context, userID int64, prefix string, limit int) ([]models.Suggestion, error)
+	GetUsage(ctx context.Context, userID int64) (models.Usage, error)
+	GetSettings(ctx context.Context, userID int64) (models.UserSettings, error)
+	UpdateSettings(ctx context.Context, userID int64, settings models.UserSettings) error
+	GetDailyNote(ctx context.Context, userID int64, date string) (models.DailyNote, error)
+	GetDailyCalendar(ctx context.Context, userID int64, month string) (models.DailyCalendar, error)
+	GetStats(ctx context.Context, userID int64, timezone string) (models.Stats, error)
+	GetDuplicates(ctx context.Context, userID int64) ([][]models.Note, error)
+	GetSummary(ctx context.Context, userID, noteID int64, sentences, keywords int) (models.NoteSummary, error)
+	GetRelated(ctx context.Context, userID, noteID int64, k int) ([]models.RelatedNote, error)
+	CheckSpelling(ctx context.Context, userID int64, text string) ([]models.SpellError, error)
+	CheckNoteSpelling(ctx context.Context, userID, noteID int64) ([]models.SpellError, error)
+	Autocorrect(ctx context.Context, userID int64, req models.AutocorrectRequest) (models.AutocorrectResult, error)
+	GetRevisions(ctx context.Context, userID, noteID int64, withSpelling bool) ([]models.NoteRevision, error)
+	RestoreRevision(ctx context.Context, userID, noteID, revisionID int64) (models.SaveNoteResult, error)
+	GetSpellingErrors(ctx context.Context, userID, noteID int64) ([]models.SpellingAnnotation, error)
+	DismissSpellingError(ctx context.Context, userID, noteID, errorID int64) error
+	GetNoteSpelling(ctx context.Context, userID, noteID int64) (models.NoteSpelling, error)
+	GetSpellcheckQueue(ctx context.Context, status string) (models.SpellcheckQueue, error)
+	RetrySpellcheckJob(ctx context.Context, jobID int64) error
+	GetDictionary(ctx context.Context, userID int64) ([]string, error)
+	AddDictionaryWords(ctx context.Context, userID int64, words []string) (added int64, err error)
+	ImportDictionary(ctx context.Context, userID int64, list string) (added int64, err error)
+	DeleteDictionaryWord(ctx context.Context, userID int64, word string) error
+
+	CreateRule(ctx context.Context, userID int64, rule models.RuleRequest) (ruleID int64, err error)
+	UpdateRule(ctx context.Context, userID, ruleID int64, rule models.RuleRequest) error
+	DeleteRule(ctx context.Context, userID, ruleID int64) error
+	GetRules(ctx context.Context, userID int64) ([]models.Rule, error)
+	DryRunRule(ctx context.Context, userID int64, rule models.RuleRequest) (affected []models.Note, err error)
+	ApplyRule(ctx context.Context, userID, ruleID int64) (models.RuleJob, error)
+	GetRuleJob(ctx context.Context, userID, jobID int64) (models.RuleJob, error)
+
+	CreateField(ctx context.Context, userID int64, field models.FieldDefinition) (fieldID int64, err error)
+	GetFields(ctx context.Context, userID int64) ([]models.FieldDefinition, error)
+	DeleteField(ctx context.Context, userID, fieldID int64) error
+
+	CreateSmartFolder(ctx context.Context, userID int64, folder models.SmartFolderRequest) (folderID int64, err error)
+	UpdateSmartFolder(ctx context.Context, userID, folderID int64, folder models.SmartFolderRequest) error
+	DeleteSmartFolder(ctx context.Context, userID, folderID int64) error
+	GetSmartFolders(ctx context.Context, userID int64) ([]models.SmartFolder, error)
+	GetSmartFolderNotes(ctx context.Context, userID, folderID int64, page, limit int) ([]models.Note, error)
 }
 
-func New(log *slog.Logger, as AuthService, ns NotesService) *Handler {
+type NotificationsService interface {
+	GetNotifications(ctx context.Context, userID int64, filter models.NotificationFilter) (notifications []models.Notification, unread int64, err error)
+	MarkRead(ctx context.Context, userID, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int64) (marked int64, err error)
+	GetPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) error
+}
+
+func New(log *slog.Logger, as AuthService, ns NotesService, nts NotificationsService, adminIDs []int64) *Handler {
+	admins := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
 	return &Handler{
-		log:          log,
-		authService:  as,
-		notesService: ns,
+		log:                  log,
+		authService:          as,
+		notesService:         ns,
+		notificationsService: nts,
+		admins:               admins,
 	}
 }
 
 func (h *Handler) InitRouter() *mux.Router {
 	r := mux.NewRouter()
 	r.Use(h.loggingMiddleware)
+	r.Use(h.clientMiddleware)
 
 	api := r.PathPrefix("/api").Subrouter()
 	{
@@ -56,8 +133,129 @@ func (h *Handler) InitRouter() *mux.Router {
 
 			notes.HandleFunc("", h.addNote).Methods(http.MethodPost)
 			notes.HandleFunc("", h.getNotes).Methods(http.MethodGet)
+			notes.HandleFunc("/duplicates", h.getDuplicates).Methods(http.MethodGet)
+			notes.HandleFunc("/search", h.searchNotes).Methods(http.MethodGet)
+			notes.HandleFunc("/suggest", h.suggestNotes).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}", h.getNote).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}", h.updateNote).Methods(http.MethodPut)
+			notes.HandleFunc("/{id:[0-9]+}", h.deleteNote).Methods(http.MethodDelete)
+			notes.HandleFunc("/{id:[0-9]+}/restore", h.restoreNote).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/activity", h.getActivity).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/summary", h.getSummary).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/related", h.getRelated).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/spellcheck", h.checkNoteSpelling).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/spelling", h.getNoteSpelling).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/spelling/{errorId:[0-9]+}/dismiss", h.dismissSpellingError).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/revisions", h.getRevisions).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/revisions/{revisionId:[0-9]+}/restore", h.restoreRevision).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/shares", h.shareNote).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/shares", h.getShares).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/shares/{userId:[0-9]+}", h.unshareNote).Methods(http.MethodDelete)
+			notes.HandleFunc("/{id:[0-9]+}/comments", h.addComment).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/comments", h.getComments).Methods(http.MethodGet)
+			notes.HandleFunc("/{id:[0-9]+}/comments/{commentId:[0-9]+}", h.updateComment).Methods(http.MethodPut)
+			notes.HandleFunc("/{id:[0-9]+}/comments/{commentId:[0-9]+}", h.deleteComment).Methods(http.MethodDelete)
+			notes.HandleFunc("/{id:[0-9]+}/comments/{commentId:[0-9]+}/resolve", h.resolveComment).Methods(http.MethodPost)
+			notes.HandleFunc("/{id:[0-9]+}/comments/{commentId:[0-9]+}/unresolve", h.unresolveComment).Methods(http.MethodPost)
+		}
+
+		rules := api.PathPrefix("/rules").Subrouter()
+		{
+			rules.Use(h.authMiddleware)
+
+			rules.HandleFunc("", h.createRule).Methods(http.MethodPost)
+			rules.HandleFunc("", h.getRules).Methods(http.MethodGet)
+			rules.HandleFunc("/dry-run", h.dryRunRule).Methods(http.MethodPost)
+			rules.HandleFunc("/jobs/{id:[0-9]+}", h.getRuleJob).Methods(http.MethodGet)
+			rules.HandleFunc("/{id:[0-9]+}", h.updateRule).Methods(http.MethodPut)
+			rules.HandleFunc("/{id:[0-9]+}", h.deleteRule).Methods(http.MethodDelete)
+			rules.HandleFunc("/{id:[0-9]+}/apply", h.applyRule).Methods(http.MethodPost)
+		}
+
+		fields := api.PathPrefix("/fields").Subrouter()
+		{
+			fields.Use(h.authMiddleware)
+
+			fields.HandleFunc("", h.createField).Methods(http.MethodPost)
+			fields.HandleFunc("", h.getFields).Methods(http.MethodGet)
+			fields.HandleFunc("/{id:[0-9]+}", h.deleteField).Methods(http.MethodDelete)
+		}
+
+		smartFolders := api.PathPrefix("/smart-folders").Subrouter()
+		{
+			smartFolders.Use(h.authMiddleware)
+
+			smartFolders.HandleFunc("", h.createSmartFolder).Methods(http.MethodPost)
+			smartFolders.HandleFunc("", h.getSmartFolders).Methods(http.MethodGet)
+			smartFolders.HandleFunc("/{id:[0-9]+}", h.updateSmartFolder).Methods(http.MethodPut)
+			smartFolders.HandleFunc("/{id:[0-9]+}", h.deleteSmartFolder).Methods(http.MethodDelete)
+			smartFolders.HandleFunc("/{id:[0-9]+}/notes", h.getSmartFolderNotes).Methods(http.MethodGet)
+		}
+
+		notifications := api.PathPrefix("/notifications").Subrouter()
+		{
+			notifications.Use(h.authMiddleware)
+
+			notifications.HandleFunc("", h.getNotifications).Methods(http.MethodGet)
+			notifications.HandleFunc("/read-all", h.markAllNotificationsRead).Methods(http.MethodPost)
+			notifications.HandleFunc("/preferences", h.getNotificationPreferences).Methods(http.MethodGet)
+			notifications.HandleFunc("/preferences", h.updateNotificationPreferences).Methods(http.MethodPut)
+			notifications.HandleFunc("/{id:[0-9]+}/read", h.markNotificationRead).Methods(http.MethodPost)
+		}
+
+		me := api.PathPrefix("/me").Subrouter()
+		{
+			me.Use(h.authMiddleware)
+
+			me.HandleFunc("/usage", h.getUsage).Methods(http.MethodGet)
+			me.HandleFunc("/settings", h.getSettings).Methods(http.MethodGet)
+			me.HandleFunc("/settings", h.updateSettings).Methods(http.MethodPut)
+			me.HandleFunc("/dictionary", h.getDictionary).Methods(http.MethodGet)
+			me.HandleFunc("/dictionary", h.addDictionaryWords).Methods(http.MethodPost)
+			me.HandleFunc("/dictionary/export", h.exportDictionary).Methods(http.MethodGet)
+			me.HandleFunc("/dictionary/import", h.importDictionary).Methods(http.MethodPost)
+			me.HandleFunc("/dictionary/{word}", h.deleteDictionaryWord).Methods(http.MethodDelete)
+		}
+
+		daily := api.PathPrefix("/daily").Subrouter()
+		{
+			daily.Use(h.authMiddleware)
+
+			daily.HandleFunc("", h.getDailyCalendar).Methods(http.MethodGet)
+			daily.HandleFunc("/{date}", h.getDailyNote).Methods(http.MethodGet)
+		}
+
+		spellcheck := api.PathPrefix("/spellcheck").Subrouter()
+		{
+			spellcheck.Use(h.authMiddleware)
+
+			spellcheck.HandleFunc("", h.checkSpelling).Methods(http.MethodPost)
+			spellcheck.HandleFunc("/apply", h.autocorrect).Methods(http.MethodPost)
+		}
+
+		stats := api.PathPrefix("/stats").Subrouter()
+		{
+			stats.Use(h.authMiddleware)
+
+			stats.HandleFunc("", h.getStats).Methods(http.MethodGet)
+		}
+
+		admin := api.PathPrefix("/admin").Subrouter()
+		{
+			admin.Use(h.authMiddleware)
+			admin.Use(h.adminMiddleware)
+
+			admin.HandleFunc("/spellcheck/jobs", h.getSpellcheckQueue).Methods(http.MethodGet)
+			admin.HandleFunc("/spellcheck/jobs/{id:[0-9]+}/retry", h.retrySpellcheckJob).Methods(http.MethodPost)
+			// expvar exposes the command line, memory stats and counters of the process
+			admin.Handle("/debug/vars", expvar.Handler()).Methods(http.MethodGet)
 		}
 	}
 
 	return r
 }
+
+// Retrieving a numeric id from the request path
+func pathID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+}
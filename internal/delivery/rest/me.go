@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) getUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	usage, err := h.notesService.GetUsage(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get usage: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get usage", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, usage)
+}
+
+func (h *Handler) getSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	settings, err := h.notesService.GetSettings(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrUserNotFound) {
+			http.Error(w, "Failed to get settings: "+noteservice.ErrUserNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to get settings: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get settings", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, settings)
+}
+
+func (h *Handler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var settings models.UserSettings
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&settings)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = settings.Validate()
+	if err != nil {
+		http.Error(w, "Invalid settings: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid settings", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.UpdateSettings(r.Context(), userID, settings)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrUserNotFound) {
+			http.Error(w, "Failed to update settings: "+noteservice.ErrUserNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to update settings: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to update settings", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
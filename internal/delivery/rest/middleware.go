@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
 	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
 )
 
@@ -26,6 +28,23 @@ func (h *Handler) loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Remembers the client address and user agent for the activity trail
+func (h *Handler) clientMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		ctx := context.WithValue(r.Context(), auth.CtxClient, models.Client{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // Authentification
 // Checks if the token is valid, and decides to let it go to endpoints or not
 func (h *Handler) authMiddleware(next http.Handler) http.Handler {
@@ -57,6 +76,20 @@ func (h *Handler) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Lets only users listed as admins in the config through, it runs after authMiddleware
+func (h *Handler) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+		if !ok || !h.admins[userID] {
+			http.Error(w, "Admin access required", http.StatusForbidden)
+			h.log.Warn("admin access denied", slog.Int64("userID", userID))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Retrieving a token from a request
 func getTokenFromRequest(r *http.Request) (string, error) {
 	header := r.Header.Get("Authorization")
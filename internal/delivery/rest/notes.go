@@ -4,13 +4,20 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/blankspace9/notes-app/internal/domain/auth"
 	"github.com/blankspace9/notes-app/internal/domain/models"
 	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
 )
 
+// maxNoteRequestSize caps the request body before the note length quota is checked
+const maxNoteRequestSize = 1 << 22
+
 func (h *Handler) addNote(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
 	if !ok {
@@ -20,10 +27,20 @@ func (h *Handler) addNote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var note models.NoteRequest
-	d := json.NewDecoder(r.Body)
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNoteRequestSize))
 
 	err := d.Decode(&note)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.writeJSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{
+				"error": "request body too large",
+				"limit": maxBytesErr.Limit,
+			})
+			h.log.Warn("request body too large", sl.Err(err))
+			return
+		}
+
 		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
 		h.log.Warn("failed to parse json", sl.Err(err))
 		return
@@ -35,35 +52,55 @@ func (h *Handler) addNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	noteID, spellingErrors, err := h.notesService.CreateNote(r.Context(), note.Note, userID)
+	note.Autocorrect = r.URL.Query().Get("autocorrect") == "true"
+
+	result, err := h.notesService.CreateNote(r.Context(), note, userID)
 	if err != nil {
+		if h.writeQuotaError(w, err) || h.writeFieldError(w, err) {
+			h.log.Warn("failed to add note", sl.Err(err))
+			return
+		}
+
 		http.Error(w, "Failed to add note: "+err.Error(), http.StatusBadRequest)
 		h.log.Warn("failed to add note", sl.Err(err))
 		return
 	}
 
-	resp, err := json.Marshal(map[string]interface{}{
-		"id":             noteID,
-		"spellingErrors": spellingErrors,
-	})
-	if err != nil {
-		http.Error(w, "Failed to marshal response json: "+err.Error(), http.StatusBadRequest)
-		h.log.Warn("failed to marshal response json", sl.Err(err))
-		return
+	h.writeJSON(w, saveNoteStatus(result), result)
+}
+
+// saveNoteStatus is 202 while the saved note waits for the background spellcheck
+func saveNoteStatus(result models.SaveNoteResult) int {
+	if result.SpellcheckStatus == models.SpellcheckPending {
+		return http.StatusAccepted
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Add("Content-Type", "application/json")
-	w.Write(resp)
+	return http.StatusOK
+}
+
+// includes reports whether the optional part is listed in ?include=a,b
+func includes(query url.Values, part string) bool {
+	for _, value := range query["include"] {
+		if slices.Contains(strings.Split(value, ","), part) {
+			return true
+		}
+	}
+
+	return false
 }
 
+// fieldParamPrefix marks query parameters filtering by custom fields: ?field.status=open&sort=field.priority
+const fieldParamPrefix = "field."
+
 func (h *Handler) getNotes(w http.ResponseWriter, r *http.Request) {
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
 	if err != nil || page <= 0 {
 		page = 0 // for all notes
 	}
 
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	limit, err := strconv.Atoi(query.Get("limit"))
 	if err != nil || limit <= 0 {
 		limit = 0 // for all notes
 	}
@@ -75,8 +112,29 @@ func (h *Handler) getNotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	notes, err := h.notesService.GetNotes(r.Context(), userID, page, limit)
+	filter := models.NoteFilter{
+		Page:      page,
+		Limit:     limit,
+		Fields:    make(map[string]interface{}),
+		SortField: strings.TrimPrefix(query.Get("sort"), fieldParamPrefix),
+		SortDesc:  query.Get("order") == "desc",
+
+		WithSummary:       includes(query, "summary"),
+		HasSpellingErrors: query.Get("hasSpellingErrors") == "true",
+	}
+	for key, values := range query {
+		if name, found := strings.CutPrefix(key, fieldParamPrefix); found {
+			filter.Fields[name] = values[0]
+		}
+	}
+
+	notes, err := h.notesService.GetNotes(r.Context(), userID, filter)
 	if err != nil {
+		if h.writeFieldError(w, err) {
+			h.log.Warn("failed to get notes", sl.Err(err))
+			return
+		}
+
 		http.Error(w, "Failed to get notes: "+err.Error(), http.StatusBadRequest)
 		h.log.Warn("failed to get notes", sl.Err(err))
 		return
@@ -96,6 +154,118 @@ func (h *Handler) getNotes(w http.ResponseWriter, r *http.Request) {
 	w.Write(resp)
 }
 
+func (h *Handler) getNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	note, err := h.notesService.GetNote(r.Context(), userID, noteID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to get note: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to get note: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get note", sl.Err(err))
+		return
+	}
+
+	if !includes(r.URL.Query(), "spelling") {
+		h.writeJSON(w, http.StatusOK, note)
+		return
+	}
+
+	spellingErrors, err := h.notesService.GetSpellingErrors(r.Context(), userID, noteID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to get spelling errors: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, models.NoteWithSpelling{Note: note, SpellingErrors: spellingErrors})
+}
+
+func (h *Handler) updateNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	var note models.NoteRequest
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNoteRequestSize))
+
+	err = d.Decode(&note)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	if note.Note == "" {
+		http.Error(w, "Empty note", http.StatusBadRequest)
+		h.log.Warn("invalid argument", sl.Err(errors.New("empty note text")))
+		return
+	}
+
+	note.Autocorrect = r.URL.Query().Get("autocorrect") == "true"
+
+	result, err := h.notesService.UpdateNote(r.Context(), note, userID, noteID)
+	if err != nil {
+		if h.writeQuotaError(w, err) || h.writeFieldError(w, err) {
+			h.log.Warn("failed to update note", sl.Err(err))
+			return
+		}
+
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to update note: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to update note: "+err.Error(), http.StatusBadRequest)
+		}
+		h.log.Warn("failed to update note", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, saveNoteStatus(result), result)
+}
+
+func (h *Handler) getDuplicates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	clusters, err := h.notesService.GetDuplicates(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get duplicates: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get duplicates", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][][]models.Note{
+		"clusters": clusters,
+	})
+}
+
 // func (h *Handler) getNotesPage(w http.ResponseWriter, r *http.Request) {
 // 	pageString := r.URL.Query().Get("page")
 // 	limitString := r.URL.Query().Get("limit")
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/services/notificationservice"
+)
+
+func (h *Handler) getNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 0 // for all notifications
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 0 // for all notifications
+	}
+
+	filter := models.NotificationFilter{
+		UnreadOnly: query.Get("unread") == "true",
+		Page:       page,
+		Limit:      limit,
+	}
+
+	notifications, unread, err := h.notificationsService.GetNotifications(r.Context(), userID, filter)
+	if err != nil {
+		http.Error(w, "Failed to get notifications: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get notifications", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+func (h *Handler) markNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	notificationID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid notification id", http.StatusBadRequest)
+		h.log.Warn("invalid notification id", sl.Err(err))
+		return
+	}
+
+	err = h.notificationsService.MarkRead(r.Context(), userID, notificationID)
+	if err != nil {
+		if errors.Is(err, notificationservice.ErrNotificationNotFound) {
+			http.Error(w, "Failed to mark notification as read: "+notificationservice.ErrNotificationNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to mark notification as read: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to mark notification as read", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) markAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	marked, err := h.notificationsService.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to mark notifications as read: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to mark notifications as read", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]int64{
+		"marked": marked,
+	})
+}
+
+func (h *Handler) getNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	prefs, err := h.notificationsService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get preferences: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get preferences", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, prefs)
+}
+
+func (h *Handler) updateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var prefs models.NotificationPreferences
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&prefs)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = prefs.Validate()
+	if err != nil {
+		http.Error(w, "Invalid preferences: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid preferences", sl.Err(err))
+		return
+	}
+
+	err = h.notificationsService.UpdatePreferences(r.Context(), userID, prefs)
+	if err != nil {
+		if errors.Is(err, notificationservice.ErrWebhookForbidden) {
+			http.Error(w, "Invalid preferences: "+err.Error(), http.StatusBadRequest)
+			h.log.Warn("forbidden webhook", sl.Err(err))
+			return
+		}
+
+		http.Error(w, "Failed to update preferences: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to update preferences", sl.Err(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
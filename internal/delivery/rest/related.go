@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+const defaultRelated = 10
+
+func (h *Handler) getRelated(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	k, err := strconv.Atoi(r.URL.Query().Get("k"))
+	if err != nil || k <= 0 {
+		k = defaultRelated
+	}
+
+	related, err := h.notesService.GetRelated(r.Context(), userID, noteID, k)
+	if err != nil {
+		switch {
+		case errors.Is(err, noteservice.ErrNoteNotFound):
+			http.Error(w, "Failed to get related notes: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, noteservice.ErrBudgetExceeded):
+			http.Error(w, "Failed to get related notes: "+noteservice.ErrBudgetExceeded.Error(), http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Failed to get related notes: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get related notes", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.RelatedNote{
+		"related": related,
+	})
+}
@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	resp, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "Failed to marshal response json: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to marshal response json", sl.Err(err))
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(resp)
+}
+
+// writeQuotaError responds with 413 for an oversized note and 429 for exhausted user quotas.
+// It returns false if err is not a quota error.
+func (h *Handler) writeQuotaError(w http.ResponseWriter, err error) bool {
+	var quotaErr *noteservice.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	status := http.StatusTooManyRequests
+	if quotaErr.Quota == noteservice.QuotaNoteLength {
+		status = http.StatusRequestEntityTooLarge
+	}
+
+	h.writeJSON(w, status, map[string]interface{}{
+		"error": quotaErr.Error(),
+		"quota": quotaErr.Quota,
+		"limit": quotaErr.Limit,
+		"used":  quotaErr.Used,
+	})
+
+	return true
+}
+
+// writeFieldError responds with 400 naming the invalid custom field.
+// It returns false if err is not a field error.
+func (h *Handler) writeFieldError(w http.ResponseWriter, err error) bool {
+	var fieldErr *noteservice.FieldError
+	if !errors.As(err, &fieldErr) {
+		return false
+	}
+
+	h.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":  fieldErr.Error(),
+		"field":  fieldErr.Field,
+		"reason": fieldErr.Reason,
+	})
+
+	return true
+}
+
+// writeRateLimitError responds with 429 and Retry-After in seconds.
+// It returns false if err is not a rate limit error.
+func (h *Handler) writeRateLimitError(w http.ResponseWriter, err error) bool {
+	var rateErr *noteservice.RateLimitError
+	if !errors.As(err, &rateErr) {
+		return false
+	}
+
+	retryAfter := int64(math.Ceil(rateErr.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+	h.writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+		"error":      rateErr.Error(),
+		"retryAfter": retryAfter,
+	})
+
+	return true
+}
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) getRevisions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	revisions, err := h.notesService.GetRevisions(r.Context(), userID, noteID, includes(r.URL.Query(), "spelling"))
+	if err != nil {
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to get revisions: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to get revisions: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get revisions", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, revisions)
+}
+
+func (h *Handler) restoreRevision(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	revisionID, err := pathID(r, "revisionId")
+	if err != nil {
+		http.Error(w, "Invalid revision id", http.StatusBadRequest)
+		h.log.Warn("invalid revision id", sl.Err(err))
+		return
+	}
+
+	result, err := h.notesService.RestoreRevision(r.Context(), userID, noteID, revisionID)
+	if err != nil {
+		if h.writeQuotaError(w, err) || h.writeFieldError(w, err) {
+			h.log.Warn("failed to restore revision", sl.Err(err))
+			return
+		}
+
+		switch {
+		case errors.Is(err, noteservice.ErrNoteNotFound):
+			http.Error(w, "Failed to restore revision: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, noteservice.ErrRevisionNotFound):
+			http.Error(w, "Failed to restore revision: "+noteservice.ErrRevisionNotFound.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to restore revision: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to restore revision", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, saveNoteStatus(result), result)
+}
@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := h.notesService.CreateRule(r.Context(), userID, rule)
+	if err != nil {
+		http.Error(w, "Failed to create rule: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to create rule", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]int64{
+		"id": id,
+	})
+}
+
+func (h *Handler) getRules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	rules, err := h.notesService.GetRules(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get rules: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get rules", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.Rule{
+		"rules": rules,
+	})
+}
+
+func (h *Handler) updateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	ruleID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid rule id", http.StatusBadRequest)
+		h.log.Warn("invalid rule id", sl.Err(err))
+		return
+	}
+
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.notesService.UpdateRule(r.Context(), userID, ruleID, rule)
+	if err != nil {
+		h.writeRuleError(w, "Failed to update rule: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	ruleID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid rule id", http.StatusBadRequest)
+		h.log.Warn("invalid rule id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.DeleteRule(r.Context(), userID, ruleID)
+	if err != nil {
+		h.writeRuleError(w, "Failed to delete rule: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) dryRunRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	notes, err := h.notesService.DryRunRule(r.Context(), userID, rule)
+	if err != nil {
+		http.Error(w, "Failed to dry run rule: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to dry run rule", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.Note{
+		"notes": notes,
+	})
+}
+
+func (h *Handler) applyRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	ruleID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid rule id", http.StatusBadRequest)
+		h.log.Warn("invalid rule id", sl.Err(err))
+		return
+	}
+
+	job, err := h.notesService.ApplyRule(r.Context(), userID, ruleID)
+	if err != nil {
+		h.writeRuleError(w, "Failed to apply rule: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) getRuleJob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	jobID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid job id", http.StatusBadRequest)
+		h.log.Warn("invalid job id", sl.Err(err))
+		return
+	}
+
+	job, err := h.notesService.GetRuleJob(r.Context(), userID, jobID)
+	if err != nil {
+		h.writeRuleError(w, "Failed to get job: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, job)
+}
+
+func (h *Handler) decodeRule(w http.ResponseWriter, r *http.Request) (models.RuleRequest, bool) {
+	var rule models.RuleRequest
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&rule)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return models.RuleRequest{}, false
+	}
+
+	// Validate fields
+	err = rule.Validate()
+	if err != nil {
+		http.Error(w, "Invalid rule: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid rule", sl.Err(err))
+		return models.RuleRequest{}, false
+	}
+
+	return rule, true
+}
+
+func (h *Handler) writeRuleError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, noteservice.ErrRuleNotFound):
+		http.Error(w, msg+noteservice.ErrRuleNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrJobNotFound):
+		http.Error(w, msg+noteservice.ErrJobNotFound.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, msg+err.Error(), http.StatusInternalServerError)
+	}
+	h.log.Warn("rule request failed", sl.Err(err))
+}
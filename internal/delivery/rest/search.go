@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+const (
+	// minSuggestPrefix shorter prefixes match too much to be useful
+	minSuggestPrefix   = 2
+	maxSuggestPrefix   = 100
+	defaultSuggestions = 10
+)
+
+func (h *Handler) searchNotes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 0 // for all notes
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 0 // for all notes
+	}
+
+	filter := models.SearchFilter{
+		Page:  page,
+		Limit: limit,
+		Fuzzy: query.Get("fuzzy") == "true",
+	}
+
+	notes, err := h.notesService.SearchNotes(r.Context(), userID, query.Get("q"), filter)
+	if err != nil {
+		var queryErr *searchquery.Error
+		if errors.As(err, &queryErr) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":    queryErr.Msg,
+				"position": queryErr.Pos,
+			})
+			h.log.Warn("invalid search query", sl.Err(err))
+			return
+		}
+
+		http.Error(w, "Failed to search notes: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to search notes", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.Note{
+		"notes": notes,
+	})
+}
+
+func (h *Handler) suggestNotes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	query := r.URL.Query()
+
+	prefix := strings.TrimSpace(query.Get("prefix"))
+	if n := utf8.RuneCountInString(prefix); n < minSuggestPrefix || n > maxSuggestPrefix {
+		http.Error(w, "Invalid prefix: length must be between 2 and 100 characters", http.StatusBadRequest)
+		h.log.Warn("invalid prefix", slog.Int("length", n))
+		return
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultSuggestions
+	}
+
+	suggestions, err := h.notesService.Suggest(r.Context(), userID, prefix, limit)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrBudgetExceeded) {
+			http.Error(w, "Failed to suggest: "+noteservice.ErrBudgetExceeded.Error(), http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, "Failed to suggest: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to suggest", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.Suggestion{
+		"suggestions": suggestions,
+	})
+}
@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) shareNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	var share models.ShareRequest
+
+	d := json.NewDecoder(r.Body)
+	err = d.Decode(&share)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = share.Validate()
+	if err != nil {
+		http.Error(w, "Invalid share: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid share", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.ShareNote(r.Context(), userID, noteID, share.Email)
+	if err != nil {
+		h.writeAccessError(w, "Failed to share note: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) unshareNote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	recipientID, err := pathID(r, "userId")
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid recipient id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.UnshareNote(r.Context(), userID, noteID, recipientID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to unshare note: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	shares, err := h.notesService.GetShares(r.Context(), userID, noteID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to get shares: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.NoteShare{
+		"shares": shares,
+	})
+}
+
+// writeAccessError maps errors of shared notes and their comments to response statuses
+func (h *Handler) writeAccessError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, noteservice.ErrNoteNotFound):
+		http.Error(w, msg+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrCommentNotFound):
+		http.Error(w, msg+noteservice.ErrCommentNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrUserNotFound):
+		http.Error(w, msg+noteservice.ErrUserNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrSpellingErrorNotFound):
+		http.Error(w, msg+noteservice.ErrSpellingErrorNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrShareNotFound):
+		http.Error(w, msg+noteservice.ErrShareNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrShareExists):
+		http.Error(w, msg+noteservice.ErrShareExists.Error(), http.StatusConflict)
+	case errors.Is(err, noteservice.ErrForbidden):
+		http.Error(w, msg+noteservice.ErrForbidden.Error(), http.StatusForbidden)
+	case errors.Is(err, noteservice.ErrShareWithSelf):
+		http.Error(w, msg+noteservice.ErrShareWithSelf.Error(), http.StatusBadRequest)
+	case errors.Is(err, noteservice.ErrInvalidAnchor):
+		http.Error(w, msg+noteservice.ErrInvalidAnchor.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, msg+err.Error(), http.StatusInternalServerError)
+	}
+	h.log.Warn("note access request failed", sl.Err(err))
+}
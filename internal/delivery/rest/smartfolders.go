@@ -0,0 +1,193 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+// defaultSmartFolderLimit is the page size of smart folder notes without ?limit
+const defaultSmartFolderLimit = 50
+
+func (h *Handler) createSmartFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	req, ok := h.decodeSmartFolder(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := h.notesService.CreateSmartFolder(r.Context(), userID, req)
+	if err != nil {
+		h.writeSmartFolderError(w, "Failed to create smart folder: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]int64{
+		"id": id,
+	})
+}
+
+func (h *Handler) getSmartFolders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	folders, err := h.notesService.GetSmartFolders(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to get smart folders: "+err.Error(), http.StatusInternalServerError)
+		h.log.Warn("failed to get smart folders", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.SmartFolder{
+		"smartFolders": folders,
+	})
+}
+
+func (h *Handler) updateSmartFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	folderID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid smart folder id", http.StatusBadRequest)
+		h.log.Warn("invalid smart folder id", sl.Err(err))
+		return
+	}
+
+	req, ok := h.decodeSmartFolder(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.notesService.UpdateSmartFolder(r.Context(), userID, folderID, req)
+	if err != nil {
+		h.writeSmartFolderError(w, "Failed to update smart folder: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) deleteSmartFolder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	folderID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid smart folder id", http.StatusBadRequest)
+		h.log.Warn("invalid smart folder id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.DeleteSmartFolder(r.Context(), userID, folderID)
+	if err != nil {
+		h.writeSmartFolderError(w, "Failed to delete smart folder: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getSmartFolderNotes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	folderID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid smart folder id", http.StatusBadRequest)
+		h.log.Warn("invalid smart folder id", sl.Err(err))
+		return
+	}
+
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultSmartFolderLimit
+	}
+
+	notes, err := h.notesService.GetSmartFolderNotes(r.Context(), userID, folderID, page, limit)
+	if err != nil {
+		h.writeSmartFolderError(w, "Failed to get smart folder notes: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string][]models.Note{
+		"notes": notes,
+	})
+}
+
+func (h *Handler) decodeSmartFolder(w http.ResponseWriter, r *http.Request) (models.SmartFolderRequest, bool) {
+	var req models.SmartFolderRequest
+
+	d := json.NewDecoder(r.Body)
+	err := d.Decode(&req)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return models.SmartFolderRequest{}, false
+	}
+
+	// Validate fields
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid smart folder: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid smart folder", sl.Err(err))
+		return models.SmartFolderRequest{}, false
+	}
+
+	return req, true
+}
+
+func (h *Handler) writeSmartFolderError(w http.ResponseWriter, msg string, err error) {
+	var queryErr *searchquery.Error
+
+	switch {
+	case errors.As(err, &queryErr):
+		h.writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":    queryErr.Msg,
+			"position": queryErr.Pos,
+		})
+	case errors.Is(err, noteservice.ErrSmartFolderNotFound):
+		http.Error(w, msg+noteservice.ErrSmartFolderNotFound.Error(), http.StatusNotFound)
+	case errors.Is(err, noteservice.ErrSmartFolderExists):
+		http.Error(w, msg+noteservice.ErrSmartFolderExists.Error(), http.StatusConflict)
+	default:
+		http.Error(w, msg+err.Error(), http.StatusInternalServerError)
+	}
+	h.log.Warn("smart folder request failed", sl.Err(err))
+}
@@ -0,0 +1,189 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) checkSpelling(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var req models.SpellcheckRequest
+
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNoteRequestSize))
+	err := d.Decode(&req)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid text: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid text", sl.Err(err))
+		return
+	}
+
+	spellingErrors, err := h.notesService.CheckSpelling(r.Context(), userID, req.Text)
+	if err != nil {
+		if h.writeQuotaError(w, err) || h.writeRateLimitError(w, err) {
+			h.log.Warn("failed to check spelling", sl.Err(err))
+			return
+		}
+
+		h.writeSpellcheckError(w, "Failed to check spelling: ", err)
+		h.log.Warn("failed to check spelling", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, spellingErrors)
+}
+
+func (h *Handler) checkNoteSpelling(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	spellingErrors, err := h.notesService.CheckNoteSpelling(r.Context(), userID, noteID)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to check note spelling: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			h.writeSpellcheckError(w, "Failed to check note spelling: ", err)
+		}
+		h.log.Warn("failed to check note spelling", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, spellingErrors)
+}
+
+func (h *Handler) autocorrect(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	var req models.AutocorrectRequest
+
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNoteRequestSize))
+	err := d.Decode(&req)
+	if err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("failed to parse json", sl.Err(err))
+		return
+	}
+
+	// Validate fields
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid text: "+err.Error(), http.StatusBadRequest)
+		h.log.Warn("invalid text", sl.Err(err))
+		return
+	}
+
+	result, err := h.notesService.Autocorrect(r.Context(), userID, req)
+	if err != nil {
+		if h.writeQuotaError(w, err) || h.writeRateLimitError(w, err) {
+			h.log.Warn("failed to autocorrect text", sl.Err(err))
+			return
+		}
+
+		h.writeSpellcheckError(w, "Failed to autocorrect text: ", err)
+		h.log.Warn("failed to autocorrect text", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// writeSpellcheckError responds with 503 when the spellchecker is unavailable
+func (h *Handler) writeSpellcheckError(w http.ResponseWriter, msg string, err error) {
+	if errors.Is(err, noteservice.ErrSpellcheckUnavailable) {
+		http.Error(w, msg+noteservice.ErrSpellcheckUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	http.Error(w, msg+err.Error(), http.StatusInternalServerError)
+}
+
+func (h *Handler) dismissSpellingError(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	errorID, err := pathID(r, "errorId")
+	if err != nil {
+		http.Error(w, "Invalid spelling error id", http.StatusBadRequest)
+		h.log.Warn("invalid spelling error id", sl.Err(err))
+		return
+	}
+
+	err = h.notesService.DismissSpellingError(r.Context(), userID, noteID, errorID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to dismiss spelling error: ", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getNoteSpelling is polled after saving a note checked in background
+func (h *Handler) getNoteSpelling(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	spelling, err := h.notesService.GetNoteSpelling(r.Context(), userID, noteID)
+	if err != nil {
+		h.writeAccessError(w, "Failed to get note spelling: ", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, spelling)
+}
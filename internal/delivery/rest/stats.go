@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+func (h *Handler) getStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	stats, err := h.notesService.GetStats(r.Context(), userID, r.URL.Query().Get("tz"))
+	if err != nil {
+		if errors.Is(err, noteservice.ErrInvalidTimezone) {
+			http.Error(w, "Failed to get stats: "+noteservice.ErrInvalidTimezone.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to get stats: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get stats", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	noteservice "github.com/blankspace9/notes-app/internal/services/noteService"
+)
+
+const (
+	defaultSummarySentences = 3
+	defaultKeywords         = 10
+)
+
+func (h *Handler) getSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(auth.CtxUserID).(int64)
+	if !ok {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		h.log.Warn("invalid user id")
+		return
+	}
+
+	noteID, err := pathID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid note id", http.StatusBadRequest)
+		h.log.Warn("invalid note id", sl.Err(err))
+		return
+	}
+
+	query := r.URL.Query()
+
+	sentences, err := strconv.Atoi(query.Get("sentences"))
+	if err != nil || sentences <= 0 {
+		sentences = defaultSummarySentences
+	}
+
+	keywords, err := strconv.Atoi(query.Get("keywords"))
+	if err != nil || keywords <= 0 {
+		keywords = defaultKeywords
+	}
+
+	summary, err := h.notesService.GetSummary(r.Context(), userID, noteID, sentences, keywords)
+	if err != nil {
+		if errors.Is(err, noteservice.ErrNoteNotFound) {
+			http.Error(w, "Failed to get summary: "+noteservice.ErrNoteNotFound.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to get summary: "+err.Error(), http.StatusInternalServerError)
+		}
+		h.log.Warn("failed to get summary", sl.Err(err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, summary)
+}
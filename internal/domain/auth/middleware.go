@@ -4,4 +4,5 @@ type CtxKey int
 
 const (
 	CtxUserID CtxKey = iota
+	CtxClient
 )
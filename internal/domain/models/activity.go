@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	ActionCreated  = "created"
+	ActionEdited   = "edited"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+	ActionShared   = "shared"
+	ActionUnshared = "unshared"
+
+	// ActionViewedPublic is reserved for views via public links, the app has no public links yet
+	ActionViewedPublic = "viewed_public"
+)
+
+type NoteActivity struct {
+	ID        int64     `json:"id"`
+	NoteID    int64     `json:"noteId"`
+	OwnerID   int64     `json:"-"`
+	ActorID   int64     `json:"actorId"`
+	Action    string    `json:"action"`
+	ClientIP  string    `json:"clientIp"`
+	UserAgent string    `json:"userAgent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Client describes where a request came from
+type Client struct {
+	IP        string
+	UserAgent string
+}
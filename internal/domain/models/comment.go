@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrReplyAnchor = errors.New("replies can't be anchored")
+
+// Comment belongs to a thread started by a root comment without a parent
+type Comment struct {
+	ID         int64      `json:"id"`
+	NoteID     int64      `json:"noteId"`
+	ParentID   int64      `json:"parentId,omitempty"`
+	AuthorID   int64      `json:"authorId"`
+	Body       string     `json:"body"`
+	Anchor     *Anchor    `json:"anchor,omitempty"`
+	Resolved   bool       `json:"resolved"`
+	ResolvedBy int64      `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+	Replies    []Comment  `json:"replies,omitempty"`
+}
+
+// Anchor is a range of the note text in runes. Detached anchors lost their text
+// after the note was edited and point to the whole note.
+type Anchor struct {
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Text     string `json:"text"`
+	Detached bool   `json:"detached,omitempty"`
+}
+
+type AnchorRequest struct {
+	Start int `json:"start" validate:"min=0"`
+	End   int `json:"end" validate:"gtfield=Start"`
+}
+
+type CommentRequest struct {
+	Body     string         `json:"body" validate:"required,max=5000"`
+	ParentID int64          `json:"parentId" validate:"min=0"`
+	Anchor   *AnchorRequest `json:"anchor"`
+}
+
+func (r CommentRequest) Validate() error {
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	if r.ParentID != 0 && r.Anchor != nil {
+		return ErrReplyAnchor
+	}
+
+	return nil
+}
+
+type CommentUpdateRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+func (r CommentUpdateRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,30 @@
+package models
+
+const (
+	DateLayout  = "2006-01-02"
+	MonthLayout = "2006-01"
+)
+
+// DailyNote is the journal note of a calendar day, Created is set when it was created by the request
+type DailyNote struct {
+	Date    string `json:"date"`
+	Created bool   `json:"created"`
+	Note    Note   `json:"note"`
+}
+
+type DailyEntry struct {
+	Date   string `json:"date"`
+	NoteID int64  `json:"noteId"`
+}
+
+// DailyCalendar lists days of the month having a journal note
+type DailyCalendar struct {
+	Month string       `json:"month"`
+	Days  []DailyEntry `json:"days"`
+}
+
+// Placeholders replaced in the journal note template, e.g. "# {{.Weekday}}, {{.Date}}"
+const (
+	DailyDate    = "{{.Date}}"
+	DailyWeekday = "{{.Weekday}}"
+)
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// DictionaryRequest adds words to the personal dictionary
+type DictionaryRequest struct {
+	Words []string `json:"words" validate:"required,min=1,max=1000,dive,min=1,max=100"`
+}
+
+func (r DictionaryRequest) Validate() error {
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return validateWords(r.Words)
+}
+
+// DictionaryImport is a word list imported at once, e.g. an exported dictionary
+type DictionaryImport struct {
+	Words []string `validate:"required,min=1,max=10000,dive,min=1,max=100"`
+}
+
+func (r DictionaryImport) Validate() error {
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return validateWords(r.Words)
+}
+
+func validateWords(words []string) error {
+	for _, word := range words {
+		if strings.ContainsFunc(word, unicode.IsSpace) {
+			return errors.New("word must not contain spaces: " + word)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package models
+
+type Fingerprint struct {
+	NoteID int64
+	Hash   uint64
+}
+
+type Duplicate struct {
+	ID         int64   `json:"id"`
+	Similarity float64 `json:"similarity"`
+}
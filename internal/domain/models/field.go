@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const (
+	FieldString  = "string"
+	FieldNumber  = "number"
+	FieldBoolean = "boolean"
+	FieldDate    = "date"
+	FieldEnum    = "enum"
+)
+
+var (
+	ErrFieldName = errors.New("field name must start with a letter and contain only letters, digits and _")
+	ErrFieldType = errors.New("unknown field type")
+	ErrFieldEnum = errors.New("enum field must have values")
+)
+
+var fieldNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// FieldDefinition describes a custom field users attach to their notes
+type FieldDefinition struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" validate:"required,max=64"`
+	Type      string    `json:"type" validate:"required"`
+	Enum      []string  `json:"enum,omitempty"`
+	Required  bool      `json:"required"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (f FieldDefinition) Validate() error {
+	if err := validate.Struct(f); err != nil {
+		return err
+	}
+
+	if !fieldNameRegexp.MatchString(f.Name) {
+		return ErrFieldName
+	}
+
+	if !slices.Contains([]string{FieldString, FieldNumber, FieldBoolean, FieldDate, FieldEnum}, f.Type) {
+		return ErrFieldType
+	}
+
+	if f.Type == FieldEnum && len(f.Enum) == 0 {
+		return ErrFieldEnum
+	}
+
+	return nil
+}
@@ -2,13 +2,56 @@ package models
 
 import "time"
 
+const (
+	SourceAPI   = "api"
+	SourceDaily = "daily"
+)
+
 type Note struct {
-	ID        int64     `json:"id"`
-	Note      string    `json:"note"`
-	UserID    int64     `json:"userID,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID          int64                  `json:"id"`
+	Note        string                 `json:"note"`
+	Summary     string                 `json:"summary,omitempty"`
+	UserID      int64                  `json:"userID,omitempty"`
+	Tags        []string               `json:"tags"`
+	Notebook    string                 `json:"notebook,omitempty"`
+	Pinned      bool                   `json:"pinned"`
+	Fields      map[string]interface{} `json:"fields"`
+	Source      string                 `json:"source"`
+	DailyDate   string                 `json:"dailyDate,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   *time.Time             `json:"updatedAt,omitempty"`
+	Fingerprint uint64                 `json:"-"`
 }
 
 type NoteRequest struct {
-	Note string `json:"note"`
+	Note     string                 `json:"note"`
+	Tags     []string               `json:"tags"`
+	Notebook string                 `json:"notebook"`
+	Pinned   bool                   `json:"pinned"`
+	Fields   map[string]interface{} `json:"fields"`
+	// Autocorrect applies spelling suggestions before saving, set by the autocorrect query parameter
+	Autocorrect bool `json:"-"`
+}
+
+// NoteFilter selects and orders notes of a user. Zero Page or Limit means all notes.
+type NoteFilter struct {
+	Page      int
+	Limit     int
+	Fields    map[string]interface{}
+	SortField string
+	SortDesc  bool
+	// WithSummary includes the cached one-line summary of every note
+	WithSummary bool
+	// HasSpellingErrors selects notes with open spelling errors
+	HasSpellingErrors bool
+}
+
+type SaveNoteResult struct {
+	ID                 int64        `json:"id"`
+	SpellingErrors     []SpellError `json:"spellingErrors"`
+	SpellcheckStatus   string       `json:"spellcheckStatus"`
+	PossibleDuplicates []Duplicate  `json:"possibleDuplicates,omitempty"`
+	AppliedRules       []int64      `json:"appliedRules,omitempty"`
+	// Autocorrect is set when suggestions were applied to the saved text
+	Autocorrect *AutocorrectResult `json:"autocorrect,omitempty"`
 }
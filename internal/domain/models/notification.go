@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const NotificationMention = "mention"
+
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"-"`
+	Kind      string     `json:"kind"`
+	ActorID   int64      `json:"actorId,omitempty"`
+	NoteID    int64      `json:"noteId,omitempty"`
+	CommentID int64      `json:"commentId,omitempty"`
+	Message   string     `json:"message"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type NotificationFilter struct {
+	UnreadOnly bool
+	Page       int
+	Limit      int
+}
+
+// NotificationPreferences choose which notifications the user gets and through which channels
+type NotificationPreferences struct {
+	Mentions   bool   `json:"mentions"`
+	InApp      bool   `json:"inApp"`
+	Email      bool   `json:"email"`
+	WebhookURL string `json:"webhookUrl" validate:"omitempty,url,startswith=http"`
+}
+
+func (p NotificationPreferences) Validate() error {
+	return validate.Struct(p)
+}
+
+// DefaultNotificationPreferences are used until the user saves his own
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		Mentions: true,
+		InApp:    true,
+	}
+}
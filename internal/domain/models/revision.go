@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Reasons of saving a revision
+const (
+	RevisionAutocorrect = "autocorrect"
+	RevisionRestore     = "restore"
+)
+
+// NoteRevision is an earlier text of the note
+type NoteRevision struct {
+	ID        int64     `json:"id"`
+	NoteID    int64     `json:"noteId"`
+	UserID    int64     `json:"-"`
+	Note      string    `json:"note"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+	// SpellingErrors of the revision text, included on request
+	SpellingErrors []SpellingAnnotation `json:"spellingErrors,omitempty"`
+}
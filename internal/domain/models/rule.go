@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+	// JobDead is a spellcheck job failed too many times, it waits for an admin
+	JobDead = "dead"
+)
+
+var (
+	ErrRuleNoConditions = errors.New("rule must have at least one condition")
+	ErrRuleNoActions    = errors.New("rule must have at least one action")
+)
+
+// Rule applies actions to notes matching all of its conditions after they are saved
+type Rule struct {
+	ID         int64          `json:"id"`
+	Name       string         `json:"name"`
+	Enabled    bool           `json:"enabled"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+type RuleConditions struct {
+	// Keywords match if the note contains any of them, case-insensitive
+	Keywords  []string `json:"keywords,omitempty"`
+	Regex     string   `json:"regex,omitempty"`
+	MinLength int      `json:"minLength,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty"`
+	Sources   []string `json:"sources,omitempty"`
+}
+
+type RuleActions struct {
+	Tags     []string `json:"tags,omitempty"`
+	Notebook string   `json:"notebook,omitempty"`
+	Pin      bool     `json:"pin,omitempty"`
+}
+
+type RuleRequest struct {
+	Name       string         `json:"name" validate:"required,max=200"`
+	Enabled    *bool          `json:"enabled"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+}
+
+func (r RuleRequest) Validate() error {
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	c := r.Conditions
+	if len(c.Keywords) == 0 && c.Regex == "" && c.MinLength == 0 && c.MaxLength == 0 && len(c.Sources) == 0 {
+		return ErrRuleNoConditions
+	}
+
+	if c.Regex != "" {
+		if _, err := regexp.Compile(c.Regex); err != nil {
+			return err
+		}
+	}
+
+	a := r.Actions
+	if len(a.Tags) == 0 && a.Notebook == "" && !a.Pin {
+		return ErrRuleNoActions
+	}
+
+	return nil
+}
+
+// RuleJob applies a rule to existing notes in background. Workers process notes page by page
+// in the order of ids, LastNoteID is the last processed one.
+type RuleJob struct {
+	ID          int64      `json:"id"`
+	RuleID      int64      `json:"ruleId"`
+	UserID      int64      `json:"-"`
+	Status      string     `json:"status"`
+	Processed   int64      `json:"processed"`
+	Matched     int64      `json:"matched"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	LastNoteID  int64      `json:"-"`
+	LockedUntil time.Time  `json:"-"`
+}
@@ -0,0 +1,20 @@
+package models
+
+const (
+	SuggestionTag   = "tag"
+	SuggestionTitle = "title"
+	SuggestionWord  = "word"
+)
+
+type SearchFilter struct {
+	Page  int
+	Limit int
+	// Fuzzy tolerates typos in words of the query
+	Fuzzy bool
+}
+
+// Suggestion completes a search prefix with a tag, the first line of a note or an indexed word
+type Suggestion struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"`
+}
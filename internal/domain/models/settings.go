@@ -0,0 +1,13 @@
+package models
+
+// UserSettings are personal preferences, empty values mean the configured defaults
+type UserSettings struct {
+	Timezone      string `json:"timezone" validate:"omitempty,timezone"`
+	DailyTemplate string `json:"dailyTemplate" validate:"max=10000"`
+	// AutocorrectExclude are words autocorrect leaves as they are, compared case-insensitively
+	AutocorrectExclude []string `json:"autocorrectExclude" validate:"max=1000,dive,min=1,max=100"`
+}
+
+func (s UserSettings) Validate() error {
+	return validate.Struct(s)
+}
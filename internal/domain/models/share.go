@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// NoteShare grants a user access to read and comment the note of another user
+type NoteShare struct {
+	NoteID    int64     `json:"noteId"`
+	UserID    int64     `json:"userId"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ShareRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r ShareRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// SmartFolderVersion is the current version of smart folder definitions
+const SmartFolderVersion = 1
+
+var (
+	ErrSmartFolderVersion = errors.New("unsupported smart folder definition version")
+	ErrSmartFolderRange   = errors.New("createdFrom must be before createdTo")
+)
+
+// SmartFolder is a named search evaluated every time it is opened
+type SmartFolder struct {
+	ID         int64                 `json:"id"`
+	Name       string                `json:"name"`
+	Definition SmartFolderDefinition `json:"definition"`
+	Count      int64                 `json:"count"`
+	CreatedAt  time.Time             `json:"createdAt"`
+	UpdatedAt  *time.Time            `json:"updatedAt,omitempty"`
+}
+
+// SmartFolderDefinition is stored as JSON, Version allows changing its format later
+type SmartFolderDefinition struct {
+	Version int `json:"version"`
+	// Query is written in the search query language
+	Query       string     `json:"query,omitempty" validate:"max=500"`
+	Tags        []string   `json:"tags,omitempty"`
+	CreatedFrom *time.Time `json:"createdFrom,omitempty"`
+	CreatedTo   *time.Time `json:"createdTo,omitempty"`
+	Sort        string     `json:"sort,omitempty" validate:"omitempty,oneof=id created updated"`
+	Order       string     `json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+}
+
+type SmartFolderRequest struct {
+	Name       string                `json:"name" validate:"required,max=200"`
+	Definition SmartFolderDefinition `json:"definition"`
+}
+
+func (r SmartFolderRequest) Validate() error {
+	if err := validate.Struct(r); err != nil {
+		return err
+	}
+
+	return r.Definition.Validate()
+}
+
+// Upgrade converts a definition stored in an older format to the current version.
+// Version 0 is the current format saved without a version.
+func (d *SmartFolderDefinition) Upgrade() {
+	d.Version = SmartFolderVersion
+}
+
+// Validate accepts definitions without a version as the current one
+func (d SmartFolderDefinition) Validate() error {
+	if d.Version < 0 || d.Version > SmartFolderVersion {
+		return ErrSmartFolderVersion
+	}
+
+	if d.CreatedFrom != nil && d.CreatedTo != nil && !d.CreatedFrom.Before(*d.CreatedTo) {
+		return ErrSmartFolderRange
+	}
+
+	return nil
+}
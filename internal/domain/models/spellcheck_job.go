@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SpellcheckJob checks the current text of the note in background. It is pending, running or dead,
+// done jobs are deleted.
+type SpellcheckJob struct {
+	ID          int64      `json:"id"`
+	NoteID      int64      `json:"noteId"`
+	UserID      int64      `json:"userId"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	Error       string     `json:"error,omitempty"`
+	RunAt       time.Time  `json:"runAt"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// SpellcheckQueue is the admin view of the job queue
+type SpellcheckQueue struct {
+	Counts map[string]int64 `json:"counts"`
+	Jobs   []SpellcheckJob  `json:"jobs"`
+}
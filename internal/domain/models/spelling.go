@@ -1,8 +1,79 @@
 package models
 
+// Error codes of the Yandex Speller API, other spellchecker backends use the same ones
+const (
+	SpellErrorUnknownWord    = 1
+	SpellErrorRepeatWord     = 2
+	SpellErrorCapitalization = 3
+	SpellErrorTooManyErrors  = 4
+)
+
+// Spellcheck statuses of a saved note. The note is saved without spelling errors
+// when the spellchecker is unavailable.
+const (
+	SpellcheckOK          = "ok"
+	SpellcheckUnavailable = "unavailable"
+	// SpellcheckPending is a note checked in background, see NoteSpelling
+	SpellcheckPending = "pending"
+)
+
+// SpellError is an error of the word at Pos counted in runes of the text
 type SpellError struct {
 	Code        int      `json:"code"`
 	Pos         int      `json:"pos"`
 	Word        string   `json:"word"`
 	Suggestions []string `json:"s"`
 }
+
+// SpellingAnnotation is a spelling error stored with the note, dismissed errors are not shown
+type SpellingAnnotation struct {
+	ID int64 `json:"id"`
+	SpellError
+	Dismissed bool `json:"dismissed"`
+}
+
+// NoteWithSpelling is the note with open spelling errors of its current text
+type NoteWithSpelling struct {
+	Note
+	SpellingErrors []SpellingAnnotation `json:"spellingErrors"`
+}
+
+// NoteSpelling is polled until the background check of the note is finished
+type NoteSpelling struct {
+	SpellcheckStatus string               `json:"spellcheckStatus"`
+	SpellingErrors   []SpellingAnnotation `json:"spellingErrors"`
+}
+
+// SpellcheckRequest is text checked without saving a note
+type SpellcheckRequest struct {
+	Text string `json:"text" validate:"required"`
+}
+
+func (r SpellcheckRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// SpellEdit replaces the misspelled word at Pos of the original text, counted in runes
+type SpellEdit struct {
+	Pos         int    `json:"pos"`
+	Word        string `json:"word"`
+	Replacement string `json:"replacement"`
+}
+
+// AutocorrectResult is the corrected text with the applied edits.
+// RevisionID is the revision keeping the original text of a saved note.
+type AutocorrectResult struct {
+	Text       string      `json:"text"`
+	Edits      []SpellEdit `json:"edits"`
+	RevisionID int64       `json:"revisionId,omitempty"`
+}
+
+// AutocorrectRequest is text corrected without saving a note, Exclude adds to the excluded words of the user
+type AutocorrectRequest struct {
+	Text    string   `json:"text" validate:"required"`
+	Exclude []string `json:"exclude" validate:"max=1000,dive,min=1,max=100"`
+}
+
+func (r AutocorrectRequest) Validate() error {
+	return validate.Struct(r)
+}
@@ -0,0 +1,22 @@
+package models
+
+// Stats are writing statistics of a user
+type Stats struct {
+	Timezone            string        `json:"timezone"`
+	Notes               int64         `json:"notes"`
+	Words               int64         `json:"words"`
+	Characters          int64         `json:"characters"`
+	AverageLength       float64       `json:"averageLength"`
+	AverageNotesPerDay  float64       `json:"averageNotesPerDay"`
+	AverageNotesPerWeek float64       `json:"averageNotesPerWeek"`
+	LongestStreak       int64         `json:"longestStreak"`
+	Weeks               []PeriodCount `json:"weeks"`
+	Heatmap             []PeriodCount `json:"heatmap"`
+}
+
+// PeriodCount is the activity of a day or of a week starting at Date (YYYY-MM-DD)
+type PeriodCount struct {
+	Date  string `json:"date"`
+	Notes int64  `json:"notes"`
+	Words int64  `json:"words"`
+}
@@ -0,0 +1,22 @@
+package models
+
+type NoteSummary struct {
+	NoteID    int64     `json:"noteId"`
+	Sentences []string  `json:"sentences"`
+	Keywords  []Keyword `json:"keywords"`
+}
+
+type Keyword struct {
+	Term  string  `json:"term"`
+	Score float64 `json:"score"`
+}
+
+type NoteScore struct {
+	NoteID int64
+	Score  float64
+}
+
+type RelatedNote struct {
+	Note  Note    `json:"note"`
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Usage is the storage consumption of a user and the configured limits (0 means unlimited)
+type Usage struct {
+	Notes         int64 `json:"notes"`
+	Bytes         int64 `json:"bytes"`
+	MaxNotes      int64 `json:"maxNotes"`
+	MaxBytes      int64 `json:"maxBytes"`
+	MaxNoteLength int64 `json:"maxNoteLength"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "github.com/go-playground/validator/v10"
+
+var validate *validator.Validate
+
+func init() {
+	validate = validator.New()
+}
@@ -0,0 +1,79 @@
+package spellchecker
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker opens after threshold failures in a row. While it is open requests
+// are rejected, after the cooldown a single probe request is let through:
+// its success closes the breaker, its failure opens it for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request may be sent and whether it is the probe of the open breaker
+func (b *circuitBreaker) allow() (probe bool, ok bool) {
+	if b.threshold <= 0 {
+		return false, true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return false, true
+	}
+
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false, false
+	}
+
+	b.probing = true
+
+	return true, true
+}
+
+// record counts the result of a request let through by allow. Requests sent before the breaker
+// opened may finish during the probe, only the probe itself ends probing.
+func (b *circuitBreaker) record(probe, failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// release forgets a request without a result, e.g. cancelled by the caller. A released probe
+// lets the next request probe the spellchecker.
+func (b *circuitBreaker) release(probe bool) {
+	if b.threshold <= 0 || !probe {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -0,0 +1,90 @@
+package spellchecker
+
+import (
+	"testing"
+	"time"
+)
+
+// openBreaker returns a breaker opened by threshold failures whose cooldown has passed
+func openBreaker() *circuitBreaker {
+	b := &circuitBreaker{threshold: 2, cooldown: time.Minute}
+	b.record(false, true)
+	b.record(false, true)
+	b.openedAt = time.Now().Add(-2 * time.Minute)
+
+	return b
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	b := &circuitBreaker{threshold: 2, cooldown: time.Minute}
+
+	b.record(false, true)
+	if _, ok := b.allow(); !ok {
+		t.Fatal("breaker opened before the threshold")
+	}
+
+	b.record(false, true)
+	if _, ok := b.allow(); ok {
+		t.Fatal("breaker is closed after the threshold")
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	b := openBreaker()
+
+	probe, ok := b.allow()
+	if !probe || !ok {
+		t.Fatalf("allow() = %v, %v, want a probe", probe, ok)
+	}
+
+	if _, ok := b.allow(); ok {
+		t.Fatal("second request is allowed during the probe")
+	}
+
+	// a request sent before the breaker opened doesn't end the probe
+	b.record(false, true)
+	if _, ok := b.allow(); ok {
+		t.Fatal("request is allowed after a result of another request")
+	}
+
+	b.record(true, false)
+	if probe, ok := b.allow(); probe || !ok {
+		t.Fatalf("allow() = %v, %v after a successful probe, want a closed breaker", probe, ok)
+	}
+}
+
+func TestCircuitBreakerFailedProbe(t *testing.T) {
+	b := openBreaker()
+
+	probe, _ := b.allow()
+	b.record(probe, true)
+
+	if _, ok := b.allow(); ok {
+		t.Fatal("request is allowed after a failed probe")
+	}
+}
+
+func TestCircuitBreakerReleasedProbe(t *testing.T) {
+	b := openBreaker()
+
+	probe, _ := b.allow()
+	b.release(probe)
+
+	// the cancelled probe neither closes the breaker nor blocks the next probe
+	probe, ok := b.allow()
+	if !probe || !ok {
+		t.Fatalf("allow() = %v, %v after a released probe, want a probe", probe, ok)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := &circuitBreaker{}
+
+	for range 10 {
+		b.record(false, true)
+	}
+
+	if probe, ok := b.allow(); probe || !ok {
+		t.Fatalf("allow() = %v, %v, want a disabled breaker to allow requests", probe, ok)
+	}
+}
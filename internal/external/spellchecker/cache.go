@@ -0,0 +1,252 @@
+package spellchecker
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"expvar"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// paragraphSeparator joins paragraphs missing in the cache into a single text
+const paragraphSeparator = "\n\n"
+
+// cacheMetrics are published at /api/admin/debug/vars
+var (
+	cacheMetrics   = expvar.NewMap("spellchecker_cache")
+	cacheHits      = new(expvar.Int)
+	cacheMisses    = new(expvar.Int)
+	cacheEvictions = new(expvar.Int)
+)
+
+func init() {
+	cacheMetrics.Set("hits", cacheHits)
+	cacheMetrics.Set("misses", cacheMisses)
+	cacheMetrics.Set("evictions", cacheEvictions)
+	cacheMetrics.Set("hitRate", expvar.Func(func() any {
+		hits, misses := cacheHits.Value(), cacheMisses.Value()
+		if hits+misses == 0 {
+			return 0.0
+		}
+
+		return float64(hits) / float64(hits+misses)
+	}))
+}
+
+// Checker is a spellchecker backend wrapped by the cache
+type Checker interface {
+	CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error)
+}
+
+// WordsChecker is a backend accepting additional correct words, e.g. a personal dictionary
+type WordsChecker interface {
+	CheckSpellingWithWords(ctx context.Context, text string, words []string) ([]models.SpellError, error)
+}
+
+// CachedSpellChecker caches errors of every paragraph, so after an edit only changed
+// paragraphs are checked. Least recently used entries are evicted when the cache is full.
+type CachedSpellChecker struct {
+	next    Checker
+	size    int
+	ttl     time.Duration
+	variant string
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+}
+
+type cacheEntry struct {
+	key       [sha256.Size]byte
+	errors    []models.SpellError
+	expiresAt time.Time
+}
+
+// NewCached wraps the backend with a cache of size paragraphs. The variant describes
+// the language and options of the backend, results of different variants do not mix.
+func NewCached(next Checker, size int, ttl time.Duration, variant string) *CachedSpellChecker {
+	return &CachedSpellChecker{
+		next:    next,
+		size:    size,
+		ttl:     ttl,
+		variant: variant,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *CachedSpellChecker) CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error) {
+	return c.CheckSpellingWithWords(ctx, text, nil)
+}
+
+// CheckSpellingWithWords passes the words to a backend accepting them, other backends check the text without them.
+// Results are cached separately for every set of words.
+func (c *CachedSpellChecker) CheckSpellingWithWords(ctx context.Context, text string, words []string) ([]models.SpellError, error) {
+	if _, ok := c.next.(WordsChecker); !ok {
+		words = nil
+	}
+
+	words = slices.Clone(words)
+	slices.Sort(words)
+	variant := c.variant + "\x00" + strings.Join(words, "\x00")
+
+	var spellErrors []models.SpellError
+	var missed []chunk
+
+	for _, p := range splitParagraphs(text) {
+		cached, found := c.get(c.key(variant, p.text))
+		if !found {
+			missed = append(missed, p)
+			continue
+		}
+
+		spellErrors = append(spellErrors, shiftErrors(cached, p.offset)...)
+	}
+
+	if len(missed) > 0 {
+		checked, err := c.checkParagraphs(ctx, missed, words, variant)
+		if err != nil {
+			return nil, err
+		}
+
+		spellErrors = append(spellErrors, checked...)
+	}
+
+	slices.SortStableFunc(spellErrors, func(a, b models.SpellError) int { return a.Pos - b.Pos })
+
+	return spellErrors, nil
+}
+
+// checkParagraphs checks the paragraphs with a single call of the backend and caches errors of each.
+// A repeated word at the start of a paragraph repeats the end of another one, it is not reported.
+func (c *CachedSpellChecker) checkParagraphs(ctx context.Context, paragraphs []chunk, words []string, variant string) ([]models.SpellError, error) {
+	texts := make([]string, len(paragraphs))
+	starts := make([]int, len(paragraphs))
+	pos := 0
+	for i, p := range paragraphs {
+		texts[i] = p.text
+		starts[i] = pos
+		pos += len([]rune(p.text)) + len([]rune(paragraphSeparator))
+	}
+
+	joined := strings.Join(texts, paragraphSeparator)
+
+	var checked []models.SpellError
+	var err error
+	if wordsChecker, ok := c.next.(WordsChecker); ok && len(words) > 0 {
+		checked, err = wordsChecker.CheckSpellingWithWords(ctx, joined, words)
+	} else {
+		checked, err = c.next.CheckSpelling(ctx, joined)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	local := make([][]models.SpellError, len(paragraphs))
+	for _, spellError := range checked {
+		i, found := slices.BinarySearch(starts, spellError.Pos)
+		if !found {
+			i--
+		}
+
+		spellError.Pos -= starts[i]
+		if spellError.Code == models.SpellErrorRepeatWord && strings.TrimSpace(string([]rune(texts[i])[:spellError.Pos])) == "" {
+			continue
+		}
+
+		local[i] = append(local[i], spellError)
+	}
+
+	var spellErrors []models.SpellError
+	for i, p := range paragraphs {
+		c.put(c.key(variant, p.text), local[i])
+		spellErrors = append(spellErrors, shiftErrors(local[i], p.offset)...)
+	}
+
+	return spellErrors, nil
+}
+
+func (c *CachedSpellChecker) key(variant, paragraph string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(variant + "\x01" + paragraph))
+}
+
+func (c *CachedSpellChecker) get(key [sha256.Size]byte) ([]models.SpellError, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.entries[key]
+	if !found {
+		cacheMisses.Add(1)
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		cacheMisses.Add(1)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	cacheHits.Add(1)
+
+	return entry.errors, true
+}
+
+func (c *CachedSpellChecker) put(key [sha256.Size]byte, spellErrors []models.SpellError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+
+	if elem, found := c.entries[key]; found {
+		entry := elem.Value.(*cacheEntry)
+		entry.errors = spellErrors
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, errors: spellErrors, expiresAt: expiresAt})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		cacheEvictions.Add(1)
+	}
+}
+
+// splitParagraphs splits the text into lines, blank lines are skipped
+func splitParagraphs(text string) []chunk {
+	var paragraphs []chunk
+
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			paragraphs = append(paragraphs, chunk{text: strings.TrimSuffix(line, "\n"), offset: offset})
+		}
+
+		offset += len([]rune(line))
+	}
+
+	return paragraphs
+}
+
+func shiftErrors(spellErrors []models.SpellError, offset int) []models.SpellError {
+	shifted := make([]models.SpellError, len(spellErrors))
+	for i, spellError := range spellErrors {
+		spellError.Pos += offset
+		shifted[i] = spellError
+	}
+
+	return shifted
+}
@@ -0,0 +1,137 @@
+package spellchecker
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// fakeChecker reports misspelled words and repeated words with rune positions, checked texts are recorded
+type fakeChecker struct {
+	misspelled map[string]string
+	texts      []string
+}
+
+func (c *fakeChecker) CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error) {
+	c.texts = append(c.texts, text)
+
+	var spellErrors []models.SpellError
+	var previous string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && unicode.IsLetter(runes[i]) {
+			i++
+		}
+		word := string(runes[start:i])
+
+		switch fix, found := c.misspelled[word]; {
+		case strings.EqualFold(word, previous):
+			spellErrors = append(spellErrors, models.SpellError{Code: models.SpellErrorRepeatWord, Pos: start, Word: word})
+		case found:
+			spellErrors = append(spellErrors, models.SpellError{Code: models.SpellErrorUnknownWord, Pos: start, Word: word, Suggestions: []string{fix}})
+		}
+		previous = word
+	}
+
+	return spellErrors, nil
+}
+
+func TestCachedSpellCheckerParagraphOffsets(t *testing.T) {
+	backend := &fakeChecker{misspelled: map[string]string{"Превед": "Привет", "дила": "дела"}}
+	c := NewCached(backend, 10, time.Hour, "test")
+
+	got, err := c.CheckSpelling(context.Background(), "Превед мир\nкак дила\n\n  \nвсё хорошо дила")
+	if err != nil {
+		t.Fatalf("CheckSpelling() error = %v", err)
+	}
+
+	want := []models.SpellError{
+		{Code: models.SpellErrorUnknownWord, Pos: 0, Word: "Превед", Suggestions: []string{"Привет"}},
+		{Code: models.SpellErrorUnknownWord, Pos: 15, Word: "дила", Suggestions: []string{"дела"}},
+		{Code: models.SpellErrorUnknownWord, Pos: 35, Word: "дила", Suggestions: []string{"дела"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckSpelling() = %+v, want %+v", got, want)
+	}
+
+	// only the edited paragraph is checked, cached errors are moved to the paragraph positions
+	got, err = c.CheckSpelling(context.Background(), "\nПревед мир\nкак дила\nвсё хорошо")
+	if err != nil {
+		t.Fatalf("CheckSpelling() after edit error = %v", err)
+	}
+
+	want = []models.SpellError{
+		{Code: models.SpellErrorUnknownWord, Pos: 1, Word: "Превед", Suggestions: []string{"Привет"}},
+		{Code: models.SpellErrorUnknownWord, Pos: 16, Word: "дила", Suggestions: []string{"дела"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckSpelling() after edit = %+v, want %+v", got, want)
+	}
+
+	if texts := []string{"Превед мир\n\nкак дила\n\nвсё хорошо дила", "всё хорошо"}; !reflect.DeepEqual(backend.texts, texts) {
+		t.Errorf("checked texts = %q, want %q", backend.texts, texts)
+	}
+}
+
+func TestCachedSpellCheckerRepeatAtParagraphStart(t *testing.T) {
+	c := NewCached(&fakeChecker{}, 10, time.Hour, "test")
+
+	// joined paragraphs put the first "да" of the second line right after the last word of the first one
+	got, err := c.CheckSpelling(context.Background(), "это да\nда нет нет")
+	if err != nil {
+		t.Fatalf("CheckSpelling() error = %v", err)
+	}
+
+	want := []models.SpellError{{Code: models.SpellErrorRepeatWord, Pos: 14, Word: "нет"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckSpelling() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCachedSpellCheckerEvictsLeastRecentlyUsed(t *testing.T) {
+	backend := &fakeChecker{}
+	c := NewCached(backend, 2, time.Hour, "test")
+
+	for _, text := range []string{"один\nдва", "один", "три", "один", "два"} {
+		if _, err := c.CheckSpelling(context.Background(), text); err != nil {
+			t.Fatalf("CheckSpelling(%q) error = %v", text, err)
+		}
+	}
+
+	// "один" is used before "три" is added, so "два" is evicted
+	if texts := []string{"один\n\nдва", "три", "два"}; !reflect.DeepEqual(backend.texts, texts) {
+		t.Errorf("checked texts = %q, want %q", backend.texts, texts)
+	}
+}
+
+func TestCachedSpellCheckerExpires(t *testing.T) {
+	backend := &fakeChecker{}
+	c := NewCached(backend, 10, 10*time.Millisecond, "test")
+
+	for range 2 {
+		if _, err := c.CheckSpelling(context.Background(), "один"); err != nil {
+			t.Fatalf("CheckSpelling() error = %v", err)
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := c.CheckSpelling(context.Background(), "один"); err != nil {
+		t.Fatalf("CheckSpelling() error = %v", err)
+	}
+
+	if texts := []string{"один", "один"}; !reflect.DeepEqual(backend.texts, texts) {
+		t.Errorf("checked texts = %q, want %q", backend.texts, texts)
+	}
+}
@@ -0,0 +1,83 @@
+package spellchecker
+
+import "unicode"
+
+// chunk is a part of the text, offset is the position of its first rune in the text
+type chunk struct {
+	text   string
+	offset int
+}
+
+// splitChunks splits the text into chunks of at most size runes. A chunk ends at the last
+// paragraph break, sentence end or space of its second half, so words are not cut in two.
+func splitChunks(text string, size int) []chunk {
+	runes := []rune(text)
+
+	var chunks []chunk
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		if end-start > size {
+			end = start + cutPosition(runes[start:start+size])
+		}
+
+		chunks = append(chunks, chunk{text: string(runes[start:end]), offset: start})
+		start = end
+	}
+
+	return chunks
+}
+
+// cutPosition returns the length of the chunk taken from the window
+func cutPosition(window []rune) int {
+	boundaries := []func(i int) bool{
+		func(i int) bool { return window[i] == '\n' },
+		func(i int) bool {
+			return i > 0 && unicode.IsSpace(window[i]) && (window[i-1] == '.' || window[i-1] == '!' || window[i-1] == '?')
+		},
+		func(i int) bool { return unicode.IsSpace(window[i]) },
+	}
+
+	for _, boundary := range boundaries {
+		for i := len(window) - 1; i >= len(window)/2; i-- {
+			if boundary(i) {
+				return i + 1
+			}
+		}
+	}
+
+	return len(window)
+}
+
+// utf16Len is the length of the text as counted by the Yandex Speller
+func utf16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16RuneLen(r)
+	}
+
+	return n
+}
+
+// runePos converts a position in UTF-16 code units of the Yandex Speller into runes
+func runePos(text string, pos int) int {
+	units, runes := 0, 0
+	for _, r := range text {
+		if units >= pos {
+			break
+		}
+
+		units += utf16RuneLen(r)
+		runes++
+	}
+
+	return runes
+}
+
+// utf16RuneLen is the number of UTF-16 code units of the rune, runes outside the BMP take a surrogate pair
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
@@ -0,0 +1,91 @@
+package spellchecker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want []chunk
+	}{
+		{name: "empty", text: "", size: 10, want: nil},
+		{name: "short text", text: "привет", size: 10, want: []chunk{{text: "привет", offset: 0}}},
+		{
+			name: "paragraph break",
+			text: "один два\nтри четыре",
+			size: 12,
+			want: []chunk{{text: "один два\n", offset: 0}, {text: "три четыре", offset: 9}},
+		},
+		{
+			name: "sentence end before a later space",
+			text: "Ну да. Нет нет нет",
+			size: 12,
+			want: []chunk{{text: "Ну да. ", offset: 0}, {text: "Нет нет нет", offset: 7}},
+		},
+		{
+			name: "space",
+			text: "один два три",
+			size: 10,
+			want: []chunk{{text: "один два ", offset: 0}, {text: "три", offset: 9}},
+		},
+		{
+			name: "boundary in the first half is ignored",
+			text: "a\nбвгдежз",
+			size: 6,
+			want: []chunk{{text: "a\nбвгд", offset: 0}, {text: "ежз", offset: 6}},
+		},
+		{
+			name: "no boundary",
+			text: "абвгдежз",
+			size: 3,
+			want: []chunk{{text: "абв", offset: 0}, {text: "где", offset: 3}, {text: "жз", offset: 6}},
+		},
+		{
+			name: "non-BMP runes count once",
+			text: "😀😀 ab",
+			size: 3,
+			want: []chunk{{text: "😀😀 ", offset: 0}, {text: "ab", offset: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitChunks(tt.text, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitChunks(%q, %d) = %+v, want %+v", tt.text, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunePos(t *testing.T) {
+	// ё takes one UTF-16 code unit, 😀 takes a surrogate pair
+	const text = "ёж 😀 да"
+
+	if got := utf16Len(text); got != 8 {
+		t.Errorf("utf16Len(%q) = %d, want 8", text, got)
+	}
+
+	tests := []struct {
+		pos  int
+		want int
+	}{
+		{pos: 0, want: 0},
+		{pos: 1, want: 1},
+		{pos: 3, want: 3},
+		{pos: 5, want: 4},
+		{pos: 6, want: 5},
+		{pos: 7, want: 6},
+		{pos: 8, want: 7},
+		{pos: 20, want: 7},
+	}
+
+	for _, tt := range tests {
+		if got := runePos(text, tt.pos); got != tt.want {
+			t.Errorf("runePos(%q, %d) = %d, want %d", text, tt.pos, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package spellchecker
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/hunspell"
+)
+
+// maxSuggestions matches the number of suggestions of the Yandex Speller
+const maxSuggestions = 5
+
+// HunspellSpellChecker checks spelling offline with Hunspell dictionaries.
+// A word is correct if any of the dictionaries accepts it.
+type HunspellSpellChecker struct {
+	dictionaries []*hunspell.Dictionary
+}
+
+// NewHunspell loads <dir>/<language>.aff and <dir>/<language>.dic for every language, e.g. ru_RU
+func NewHunspell(dir string, languages []string) (*HunspellSpellChecker, error) {
+	const op = "external.HunspellSpellChecker.New"
+
+	if len(languages) == 0 {
+		return nil, fmt.Errorf("%s: no languages", op)
+	}
+
+	sc := &HunspellSpellChecker{}
+	for _, lang := range languages {
+		base := filepath.Join(dir, strings.TrimSpace(lang))
+
+		dict, err := hunspell.Load(base+".aff", base+".dic")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, lang, err)
+		}
+
+		sc.dictionaries = append(sc.dictionaries, dict)
+	}
+
+	return sc, nil
+}
+
+func (sc *HunspellSpellChecker) CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error) {
+	return sc.CheckSpellingWithWords(ctx, text, nil)
+}
+
+// CheckSpellingWithWords accepts the words as correct in any case in addition to the dictionaries,
+// so they are recognized in hyphenated words too
+func (sc *HunspellSpellChecker) CheckSpellingWithWords(ctx context.Context, text string, words []string) ([]models.SpellError, error) {
+	known := make(map[string]bool, len(words))
+	for _, w := range words {
+		known[strings.ToLower(w)] = true
+	}
+
+	var spellErrors []models.SpellError
+
+	runes := []rune(text)
+
+	var prev word
+	for _, w := range splitWords(runes) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// "word word" is a repetition, "word, word" is not
+		gap := ""
+		if prev.text != "" {
+			gap = string(runes[prev.pos+len([]rune(prev.text)) : w.pos])
+		}
+
+		if prev.text != "" && strings.EqualFold(prev.text, w.text) && strings.TrimSpace(gap) == "" {
+			spellErrors = append(spellErrors, models.SpellError{
+				Code: models.SpellErrorRepeatWord, Pos: w.pos, Word: w.text, Suggestions: []string{w.text},
+			})
+		}
+		prev = w
+
+		spellErrors = append(spellErrors, sc.checkWord(w, known)...)
+	}
+
+	return spellErrors, nil
+}
+
+// checkWord reports an unknown word or a proper noun written in lower case.
+// Parts of an unknown hyphenated word are checked separately.
+func (sc *HunspellSpellChecker) checkWord(w word, known map[string]bool) []models.SpellError {
+	if sc.check(w.text, known) {
+		return nil
+	}
+
+	if parts := strings.Split(w.text, "-"); len(parts) > 1 {
+		var spellErrors []models.SpellError
+
+		pos := w.pos
+		for _, part := range parts {
+			if part != "" {
+				spellErrors = append(spellErrors, sc.checkWord(word{text: part, pos: pos}, known)...)
+			}
+			pos += len([]rune(part)) + 1
+		}
+
+		return spellErrors
+	}
+
+	if capitalized := title(w.text); capitalized != w.text && sc.check(capitalized, known) {
+		return []models.SpellError{{
+			Code: models.SpellErrorCapitalization, Pos: w.pos, Word: w.text, Suggestions: []string{capitalized},
+		}}
+	}
+
+	return []models.SpellError{{
+		Code: models.SpellErrorUnknownWord, Pos: w.pos, Word: w.text, Suggestions: sc.suggest(w.text),
+	}}
+}
+
+func (sc *HunspellSpellChecker) check(text string, known map[string]bool) bool {
+	if known[strings.ToLower(text)] {
+		return true
+	}
+
+	for _, dict := range sc.dictionaries {
+		if dict.Check(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sc *HunspellSpellChecker) suggest(text string) []string {
+	suggestions := []string{}
+	for _, dict := range sc.dictionaries {
+		if len(suggestions) >= maxSuggestions {
+			break
+		}
+
+		suggestions = append(suggestions, dict.Suggest(text, maxSuggestions-len(suggestions))...)
+	}
+
+	return suggestions
+}
+
+type word struct {
+	text string
+	// pos is the offset in runes from the start of the text
+	pos int
+}
+
+// splitWords extracts words with inner hyphens and apostrophes skipping URLs, emails and words with digits
+func splitWords(runes []rune) []word {
+	var words []word
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		// a chunk is delimited by spaces
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+
+		chunk := string(runes[start:i])
+		if strings.Contains(chunk, "://") || strings.Contains(chunk, "@") || strings.HasPrefix(chunk, "www.") {
+			continue
+		}
+
+		for j := start; j < i; {
+			if !isWordRune(runes[j]) {
+				j++
+				continue
+			}
+
+			wordStart := j
+			for j < i && (isWordRune(runes[j]) || isInnerRune(runes, j, i)) {
+				j++
+			}
+
+			text := string(runes[wordStart:j])
+			if !strings.ContainsFunc(text, unicode.IsNumber) {
+				words = append(words, word{text: text, pos: wordStart})
+			}
+		}
+	}
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// isInnerRune accepts hyphens and apostrophes between letters: "что-то", "don't"
+func isInnerRune(runes []rune, j, end int) bool {
+	switch runes[j] {
+	case '-', '\'', '’':
+		return j > 0 && j+1 < end && isWordRune(runes[j-1]) && isWordRune(runes[j+1])
+	default:
+		return false
+	}
+}
+
+func title(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return word
+	}
+
+	return strings.ToUpper(string(runes[0])) + string(runes[1:])
+}
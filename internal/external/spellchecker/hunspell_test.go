@@ -0,0 +1,71 @@
+package spellchecker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func TestHunspellCheckSpelling(t *testing.T) {
+	sc, err := NewHunspell("testdata", []string{"en_US"})
+	if err != nil {
+		t.Fatalf("NewHunspell() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		words []string
+		want  []models.SpellError
+	}{
+		{
+			name: "correct",
+			text: "Hello, world! Paris is a city.",
+		},
+		{
+			name: "codes and positions in runes",
+			text: "Привет the the wrold, paris is a city-cty.",
+			want: []models.SpellError{
+				{Code: models.SpellErrorUnknownWord, Pos: 0, Word: "Привет", Suggestions: []string{}},
+				{Code: models.SpellErrorRepeatWord, Pos: 11, Word: "the", Suggestions: []string{"the"}},
+				{Code: models.SpellErrorUnknownWord, Pos: 15, Word: "wrold", Suggestions: []string{"world"}},
+				{Code: models.SpellErrorCapitalization, Pos: 22, Word: "paris", Suggestions: []string{"Paris"}},
+				{Code: models.SpellErrorUnknownWord, Pos: 38, Word: "cty", Suggestions: []string{"city"}},
+			},
+		},
+		{
+			name: "repetition across punctuation",
+			text: "the, the",
+		},
+		{
+			name: "urls, emails and words with digits are skipped",
+			text: "https://exmple.com mail@exmple.com www.exmple.com 2nd",
+		},
+		{
+			name:  "personal words",
+			text:  "Wrold worlds",
+			words: []string{"wrold"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sc.CheckSpellingWithWords(context.Background(), tt.text, tt.words)
+			if err != nil {
+				t.Fatalf("CheckSpellingWithWords() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckSpellingWithWords(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHunspellMissingDictionary(t *testing.T) {
+	if _, err := NewHunspell("testdata", []string{"de_DE"}); err == nil {
+		t.Error("NewHunspell() error = nil, want missing dictionary")
+	}
+}
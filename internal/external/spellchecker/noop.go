@@ -0,0 +1,18 @@
+package spellchecker
+
+import (
+	"context"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// NoopSpellChecker finds no errors, it disables spellchecking
+type NoopSpellChecker struct{}
+
+func NewNoop() *NoopSpellChecker {
+	return &NoopSpellChecker{}
+}
+
+func (sc *NoopSpellChecker) CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error) {
+	return nil, nil
+}
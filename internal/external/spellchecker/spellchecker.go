@@ -1,40 +1,276 @@
 package spellchecker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/blankspace9/notes-app/internal/domain/models"
 )
 
+const (
+	// chunkLength limits a text of a request in runes
+	chunkLength = 2000
+	// batchLength is the limit of the Yandex Speller on the length of all texts of a request
+	batchLength = 10000
+	// maxParallel limits concurrent requests checking a single note
+	maxParallel = 4
+)
+
+// ErrUnavailable is returned without a request while the circuit breaker is open
+var ErrUnavailable = errors.New("spellchecker is unavailable")
+
+// StatusError is a non-200 response of the spellchecker
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("spellchecker responded with status %d", e.StatusCode)
+}
+
 type YandexSpellChecker struct {
-	url string
+	url     string
+	client  *http.Client
+	retries int
+	backoff time.Duration
+	breaker *circuitBreaker
+}
+
+type Option func(*YandexSpellChecker)
+
+// WithTimeouts limits establishing a connection and a single attempt of the request
+func WithTimeouts(connect, request time.Duration) Option {
+	return func(sc *YandexSpellChecker) {
+		sc.client = newClient(connect, request)
+	}
 }
 
-func New(url string) *YandexSpellChecker {
-	return &YandexSpellChecker{
-		url: url,
+// WithRetries repeats requests failed with 5xx or a network error, the pause before
+// the n-th retry is a random duration between backoff*2^(n-1)/2 and backoff*2^(n-1)
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(sc *YandexSpellChecker) {
+		sc.retries = retries
+		sc.backoff = backoff
+	}
+}
+
+// WithCircuitBreaker stops requests for the cooldown after threshold failures in a row, 0 disables it
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(sc *YandexSpellChecker) {
+		sc.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+// New creates the client of the Yandex Speller. Texts are sent in batches to checkTexts,
+// the url of checkText is accepted too.
+func New(url string, opts ...Option) *YandexSpellChecker {
+	if strings.HasSuffix(url, "/checkText") {
+		url += "s"
+	}
+
+	sc := &YandexSpellChecker{
+		url:     url,
+		client:  newClient(time.Second, 3*time.Second),
+		breaker: &circuitBreaker{},
+	}
+
+	for _, opt := range opts {
+		opt(sc)
 	}
+
+	return sc
+}
+
+func newClient(connect, request time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connect
+
+	return &http.Client{Transport: transport, Timeout: request}
 }
 
-func (sc *YandexSpellChecker) CheckSpelling(text string) ([]models.SpellError, error) {
+func (sc *YandexSpellChecker) CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error) {
 	const op = "external.YandexSpellChecker.CheckSpelling"
 
-	resp, err := http.Get(fmt.Sprintf("%s?text=%s", sc.url, url.QueryEscape(text)))
+	probe, ok := sc.breaker.allow()
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, ErrUnavailable)
+	}
+
+	spellErrors, err := sc.checkChunks(ctx, splitChunks(text, chunkLength))
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		// a request cancelled by the caller says nothing about the spellchecker
+		sc.breaker.release(probe)
+	} else {
+		sc.breaker.record(probe, err != nil && temporary(err))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer resp.Body.Close()
+
+	return spellErrors, nil
+}
+
+// checkChunks sends batches of chunks in parallel, the first failure cancels other requests.
+// Positions of errors are converted to runes of the whole text.
+func (sc *YandexSpellChecker) checkChunks(ctx context.Context, chunks []chunk) ([]models.SpellError, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := batchChunks(chunks)
+	results := make([][]models.SpellError, len(batches))
+	errs := make([]error, len(batches))
+
+	sem := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = sc.checkWithRetries(ctx, batch)
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
 
 	var spellErrors []models.SpellError
+	var firstErr error
+	for i, err := range errs {
+		// requests cancelled after a failure hide its cause
+		if err != nil && (firstErr == nil || errors.Is(firstErr, context.Canceled)) {
+			firstErr = err
+		}
+
+		spellErrors = append(spellErrors, results[i]...)
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return spellErrors, nil
+}
+
+// batchChunks groups consecutive chunks into requests within the batch length
+func batchChunks(chunks []chunk) [][]chunk {
+	var batches [][]chunk
+
+	length := 0
+	for _, c := range chunks {
+		n := utf16Len(c.text)
+		if len(batches) == 0 || length+n > batchLength {
+			batches = append(batches, nil)
+			length = 0
+		}
+
+		batches[len(batches)-1] = append(batches[len(batches)-1], c)
+		length += n
+	}
+
+	return batches
+}
+
+// checkWithRetries stops retrying when the context is done, its deadline is the budget of all attempts
+func (sc *YandexSpellChecker) checkWithRetries(ctx context.Context, batch []chunk) ([]models.SpellError, error) {
+	for attempt := 0; ; attempt++ {
+		spellErrors, err := sc.check(ctx, batch)
+		if err == nil || attempt >= sc.retries || !temporary(err) || ctx.Err() != nil {
+			return spellErrors, err
+		}
+
+		timer := time.NewTimer(sc.pause(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// check sends the texts of the batch as a form, the response has errors of every text
+func (sc *YandexSpellChecker) check(ctx context.Context, batch []chunk) ([]models.SpellError, error) {
+	form := url.Values{}
+	for _, c := range batch {
+		form.Add("text", c.text)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sc.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := sc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var results [][]models.SpellError
 	d := json.NewDecoder(resp.Body)
 
-	err = d.Decode(&spellErrors)
+	err = d.Decode(&results)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, err
+	}
+
+	if len(results) != len(batch) {
+		return nil, fmt.Errorf("spellchecker returned %d results for %d texts", len(results), len(batch))
+	}
+
+	var spellErrors []models.SpellError
+	for i, c := range batch {
+		for _, spellError := range results[i] {
+			spellError.Pos = c.offset + runePos(c.text, spellError.Pos)
+			spellErrors = append(spellErrors, spellError)
+		}
 	}
 
 	return spellErrors, nil
 }
+
+// pause returns the exponential backoff with jitter, so clients do not retry in lockstep
+func (sc *YandexSpellChecker) pause(attempt int) time.Duration {
+	d := sc.backoff << attempt
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + rand.N(d/2+1)
+}
+
+// temporary reports whether the request may succeed when repeated: 5xx responses
+// and network errors including timeouts. Other responses are not retried.
+func temporary(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,158 @@
+package hunspell
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type flag uint32
+
+type flagMode int
+
+const (
+	flagChar flagMode = iota
+	flagLong
+	flagNum
+)
+
+// parseFlags decodes a flag string of the dictionary. With aliases (AF) flags are the alias number.
+func (d *Dictionary) parseFlags(s string) []flag {
+	if len(d.aliases) > 0 {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= len(d.aliases) {
+			return d.aliases[n-1]
+		}
+	}
+
+	return parseFlagString(s, d.mode)
+}
+
+func parseFlagString(s string, mode flagMode) []flag {
+	var flags []flag
+
+	switch mode {
+	case flagLong:
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags = append(flags, flag(runes[i])<<16|flag(runes[i+1]))
+		}
+	case flagNum:
+		for _, part := range strings.Split(s, ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+				flags = append(flags, flag(n))
+			}
+		}
+	default:
+		for _, r := range s {
+			flags = append(flags, flag(r))
+		}
+	}
+
+	return flags
+}
+
+func hasFlag(flags []flag, f flag) bool {
+	if f == 0 {
+		return false
+	}
+
+	for _, x := range flags {
+		if x == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// affix is a prefix or suffix rule: strip is removed from the root and add is appended
+// when the root matches the condition
+type affix struct {
+	flag  flag
+	cross bool
+	strip string
+	add   string
+	cond  condition
+	// cont are continuation flags, they allow affixes only together with another one
+	cont []flag
+}
+
+// condition is a simplified regular expression of hunspell: a sequence of characters,
+// character classes [abc], negated classes [^abc] and the wildcard "."
+type condition []charClass
+
+type charClass struct {
+	any    bool
+	negate bool
+	chars  string
+}
+
+func parseCondition(s string) condition {
+	if s == "." || s == "" {
+		return nil
+	}
+
+	var cond condition
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '.':
+			cond = append(cond, charClass{any: true})
+			i++
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				end = len(s) - i
+			}
+
+			class := s[i+1 : i+end]
+			negate := strings.HasPrefix(class, "^")
+			cond = append(cond, charClass{negate: negate, chars: strings.TrimPrefix(class, "^")})
+			i += end + 1
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			cond = append(cond, charClass{chars: string(r)})
+			i += size
+		}
+	}
+
+	return cond
+}
+
+func (c charClass) match(r rune) bool {
+	if c.any {
+		return true
+	}
+
+	return strings.ContainsRune(c.chars, r) != c.negate
+}
+
+// matchEnd checks the condition of a suffix against the end of the root
+func (c condition) matchEnd(root []rune) bool {
+	if len(c) > len(root) {
+		return false
+	}
+
+	offset := len(root) - len(c)
+	for i, class := range c {
+		if !class.match(root[offset+i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchStart checks the condition of a prefix against the start of the root
+func (c condition) matchStart(root []rune) bool {
+	if len(c) > len(root) {
+		return false
+	}
+
+	for i, class := range c {
+		if !class.match(root[i]) {
+			return false
+		}
+	}
+
+	return true
+}
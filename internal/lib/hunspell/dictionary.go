@@ -0,0 +1,389 @@
+// Package hunspell checks spelling with Hunspell .aff/.dic dictionaries without external libraries.
+//
+// It supports what common dictionaries rely on: prefixes and suffixes with cross products,
+// flag formats (FLAG long/num/UTF-8, AF aliases), FORBIDDENWORD, NEEDAFFIX, NOSUGGEST
+// and suggestions from REP, KEY and TRY. Compounding and twofold suffixes are not supported.
+package hunspell
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// MaxWordLength in runes bounds checked words like MAXWORDLEN of Hunspell: longer words
+// are reported misspelled without suggestions, their candidates would cost too much
+const MaxWordLength = 100
+
+type Dictionary struct {
+	words map[string][][]flag
+
+	// affixes by the added text, so a word is matched by its ending or beginning
+	prefixes  map[string][]*affix
+	suffixes  map[string][]*affix
+	maxPrefix int
+	maxSuffix int
+
+	mode    flagMode
+	aliases [][]flag
+
+	try []rune
+	key []string
+	rep [][2]string
+
+	forbidden flag
+	needAffix flag
+	noSuggest flag
+}
+
+// Load reads the dictionary from the affix and word list files
+func Load(affPath, dicPath string) (*Dictionary, error) {
+	aff, err := os.ReadFile(affPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dic, err := os.ReadFile(dicPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(aff, dic)
+}
+
+// Parse builds the dictionary from contents of the files in the encoding set by the SET directive
+func Parse(aff, dic []byte) (*Dictionary, error) {
+	d := &Dictionary{
+		words:    make(map[string][][]flag),
+		prefixes: make(map[string][]*affix),
+		suffixes: make(map[string][]*affix),
+	}
+
+	affText, err := decode(aff, aff)
+	if err != nil {
+		return nil, err
+	}
+
+	dicText, err := decode(aff, dic)
+	if err != nil {
+		return nil, err
+	}
+
+	d.parseAff(affText)
+	d.parseDic(dicText)
+
+	return d, nil
+}
+
+// decode converts data to UTF-8 from the encoding declared in the affix file
+func decode(aff, data []byte) (string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	encoding := "UTF-8"
+	for _, line := range bytes.Split(aff, []byte("\n")) {
+		fields := strings.Fields(string(line))
+		if len(fields) >= 2 && fields[0] == "SET" {
+			encoding = fields[1]
+			break
+		}
+	}
+
+	name := strings.ToLower(encoding)
+	if name == "utf-8" {
+		return string(data), nil
+	}
+
+	name = strings.Replace(name, "microsoft-cp", "windows-", 1)
+	if rest, found := strings.CutPrefix(name, "iso8859"); found {
+		name = "iso-8859" + rest
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("unsupported encoding %s: %w", encoding, err)
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(decoded), nil
+}
+
+func (d *Dictionary) parseAff(text string) {
+	// affix classes whose header was read, the header of a class precedes its rules
+	headers := make(map[string]bool)
+	aliasCount := -1
+
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "FLAG":
+			switch fields[1] {
+			case "long":
+				d.mode = flagLong
+			case "num":
+				d.mode = flagNum
+			}
+		case "TRY":
+			d.try = []rune(fields[1])
+		case "KEY":
+			d.key = strings.Split(fields[1], "|")
+		case "REP":
+			if len(fields) >= 3 {
+				d.rep = append(d.rep, [2]string{
+					strings.ReplaceAll(fields[1], "_", " "),
+					strings.ReplaceAll(fields[2], "_", " "),
+				})
+			}
+		case "AF":
+			if aliasCount < 0 {
+				aliasCount, _ = strconv.Atoi(fields[1])
+				continue
+			}
+
+			d.aliases = append(d.aliases, parseFlagString(fields[1], d.mode))
+		case "FORBIDDENWORD":
+			d.forbidden = firstFlag(fields[1], d.mode)
+		case "NEEDAFFIX", "PSEUDOROOT":
+			d.needAffix = firstFlag(fields[1], d.mode)
+		case "NOSUGGEST":
+			d.noSuggest = firstFlag(fields[1], d.mode)
+		case "PFX", "SFX":
+			if len(fields) < 4 {
+				continue
+			}
+
+			key := fields[0] + fields[1]
+			if _, found := headers[key]; !found {
+				headers[key] = fields[2] == "Y"
+				continue
+			}
+
+			d.addAffix(fields, headers[key])
+		}
+	}
+}
+
+func firstFlag(s string, mode flagMode) flag {
+	flags := parseFlagString(s, mode)
+	if len(flags) == 0 {
+		return 0
+	}
+
+	return flags[0]
+}
+
+// addAffix adds the rule "SFX flag strip add[/flags] condition"
+func (d *Dictionary) addAffix(fields []string, cross bool) {
+	flags := parseFlagString(fields[1], d.mode)
+	if len(flags) == 0 {
+		return
+	}
+
+	a := &affix{flag: flags[0], cross: cross, strip: fields[2]}
+	if a.strip == "0" {
+		a.strip = ""
+	}
+
+	add, cont, _ := strings.Cut(fields[3], "/")
+	if add == "0" {
+		add = ""
+	}
+	a.add = add
+	if cont != "" {
+		a.cont = d.parseFlags(cont)
+	}
+
+	if len(fields) >= 5 {
+		a.cond = parseCondition(fields[4])
+	}
+
+	size := len([]rune(add))
+	if fields[0] == "PFX" {
+		d.prefixes[add] = append(d.prefixes[add], a)
+		d.maxPrefix = max(d.maxPrefix, size)
+	} else {
+		d.suffixes[add] = append(d.suffixes[add], a)
+		d.maxSuffix = max(d.maxSuffix, size)
+	}
+}
+
+func (d *Dictionary) parseDic(text string) {
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// the first line is the approximate number of words
+		if i == 0 {
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				continue
+			}
+		}
+
+		word, flags := splitEntry(fields[0])
+		if word == "" {
+			continue
+		}
+
+		d.words[word] = append(d.words[word], d.parseFlags(flags))
+	}
+}
+
+// splitEntry separates "word/flags", a slash inside the word is escaped as \/
+func splitEntry(entry string) (string, string) {
+	for i := 0; i < len(entry); i++ {
+		if entry[i] == '/' && (i == 0 || entry[i-1] != '\\') {
+			return strings.ReplaceAll(entry[:i], `\/`, "/"), entry[i+1:]
+		}
+	}
+
+	return strings.ReplaceAll(entry, `\/`, "/"), ""
+}
+
+// Check reports whether the word is spelled correctly. Capitalized and upper case
+// words are also checked in lower case, proper nouns must stay capitalized.
+func (d *Dictionary) Check(word string) bool {
+	if utf8.RuneCountInString(word) > MaxWordLength {
+		return false
+	}
+
+	for _, variant := range caseVariants(word) {
+		if d.check(variant, false) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func caseVariants(word string) []string {
+	lower := strings.ToLower(word)
+
+	switch {
+	case word == lower:
+		return []string{word}
+	case word == strings.ToUpper(word):
+		return []string{word, title(lower), lower}
+	case word == title(lower):
+		return []string{word, lower}
+	default:
+		return []string{word}
+	}
+}
+
+func title(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return word
+	}
+
+	return strings.ToUpper(string(runes[0])) + string(runes[1:])
+}
+
+// check matches the exact word form. Suggestions skip words marked with NOSUGGEST.
+func (d *Dictionary) check(word string, suggest bool) bool {
+	for _, flags := range d.words[word] {
+		if hasFlag(flags, d.forbidden) {
+			return false
+		}
+	}
+
+	if d.lookup(word, suggest, func(flags []flag) bool { return !hasFlag(flags, d.needAffix) }) {
+		return true
+	}
+
+	runes := []rune(word)
+
+	return d.checkSuffix(runes, nil, suggest) || d.checkPrefix(runes, suggest)
+}
+
+// lookup reports whether the root has a dictionary entry accepted by the function
+func (d *Dictionary) lookup(root string, suggest bool, accept func(flags []flag) bool) bool {
+	for _, flags := range d.words[root] {
+		if hasFlag(flags, d.forbidden) || (suggest && hasFlag(flags, d.noSuggest)) {
+			continue
+		}
+
+		if accept(flags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSuffix strips a suffix from the word. After a prefix was stripped the root must allow both affixes.
+func (d *Dictionary) checkSuffix(word []rune, pfx *affix, suggest bool) bool {
+	n := len(word)
+
+	for k := 0; k <= min(n, d.maxSuffix); k++ {
+		for _, sfx := range d.suffixes[string(word[n-k:])] {
+			if pfx != nil && !sfx.cross {
+				continue
+			}
+
+			// the suffix needs another affix, twofold suffixes are not supported
+			if hasFlag(sfx.cont, d.needAffix) {
+				continue
+			}
+
+			root := append(slices.Clone(word[:n-k]), []rune(sfx.strip)...)
+			if len(root) == 0 || !sfx.cond.matchEnd(root) {
+				continue
+			}
+
+			found := d.lookup(string(root), suggest, func(flags []flag) bool {
+				return hasFlag(flags, sfx.flag) && (pfx == nil || hasFlag(flags, pfx.flag) || hasFlag(sfx.cont, pfx.flag))
+			})
+			if found {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (d *Dictionary) checkPrefix(word []rune, suggest bool) bool {
+	n := len(word)
+
+	for k := 0; k <= min(n, d.maxPrefix); k++ {
+		for _, pfx := range d.prefixes[string(word[:k])] {
+			if hasFlag(pfx.cont, d.needAffix) && !pfx.cross {
+				continue
+			}
+
+			root := append([]rune(pfx.strip), word[k:]...)
+			if len(root) == 0 || !pfx.cond.matchStart(root) {
+				continue
+			}
+
+			found := d.lookup(string(root), suggest, func(flags []flag) bool {
+				return hasFlag(flags, pfx.flag) && !hasFlag(pfx.cont, d.needAffix)
+			})
+			if found {
+				return true
+			}
+
+			if pfx.cross && d.checkSuffix(root, pfx, suggest) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
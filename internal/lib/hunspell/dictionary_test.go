@@ -0,0 +1,146 @@
+package hunspell
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func load(t *testing.T, name string) *Dictionary {
+	t.Helper()
+
+	base := filepath.Join("testdata", name)
+
+	d, err := Load(base+".aff", base+".dic")
+	if err != nil {
+		t.Fatalf("Load(%s) error = %v", name, err)
+	}
+
+	return d
+}
+
+func TestCheck(t *testing.T) {
+	d := load(t, "en")
+
+	tests := []struct {
+		word string
+		want bool
+	}{
+		// roots
+		{word: "work", want: true},
+		{word: "hello", want: true},
+		{word: "path/name", want: true},
+		{word: "worke", want: false},
+		// suffixes with strip and conditions
+		{word: "works", want: true},
+		{word: "worked", want: true},
+		{word: "cities", want: true},
+		{word: "citys", want: false},
+		{word: "boxes", want: true},
+		{word: "boxs", want: false},
+		{word: "baked", want: true},
+		{word: "bakeed", want: false},
+		{word: "baking", want: true},
+		// prefixes
+		{word: "rework", want: true},
+		{word: "unkind", want: true},
+		{word: "unwork", want: false},
+		// cross products
+		{word: "reworks", want: true},
+		{word: "reworked", want: true},
+		{word: "kinds", want: true},
+		{word: "unkinds", want: false},
+		// flags
+		{word: "colour", want: false},
+		{word: "damn", want: true},
+		{word: "pant", want: false},
+		{word: "pants", want: true},
+		// case
+		{word: "Work", want: true},
+		{word: "WORK", want: true},
+		{word: "Reworked", want: true},
+		{word: "Paris", want: true},
+		{word: "PARIS", want: true},
+		{word: "paris", want: false},
+		{word: "wOrk", want: false},
+		// length
+		{word: strings.Repeat("work", MaxWordLength/4+1), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := d.Check(tt.word); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAliases(t *testing.T) {
+	d := load(t, "aliases")
+
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{word: "view", want: true},
+		{word: "views", want: true},
+		{word: "preview", want: true},
+		{word: "previews", want: true},
+		{word: "cat", want: true},
+		{word: "cats", want: true},
+		{word: "precat", want: false},
+		{word: "precats", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := d.Check(tt.word); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestParseFlagModes(t *testing.T) {
+	tests := []struct {
+		name string
+		aff  string
+		dic  string
+		word string
+	}{
+		{
+			name: "num",
+			aff:  "FLAG num\nSFX 101 Y 1\nSFX 101 0 s .\n",
+			dic:  "1\ndog/7,101\n",
+			word: "dogs",
+		},
+		{
+			name: "UTF-8",
+			aff:  "SFX ф Y 1\nSFX ф 0 ы .\n",
+			dic:  "1\nдом/ф\n",
+			word: "домы",
+		},
+		{
+			name: "encoding",
+			aff:  "SET microsoft-cp1251\nSFX A Y 1\nSFX A 0 \xfb .\n",
+			dic:  "1\n\xec\xe8\xf0/A\n",
+			word: "миры",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse([]byte(tt.aff), []byte(tt.dic))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if !d.Check(tt.word) {
+				t.Errorf("Check(%q) = false, want true", tt.word)
+			}
+		})
+	}
+}
+
+func TestParseUnsupportedEncoding(t *testing.T) {
+	if _, err := Parse([]byte("SET X-UNKNOWN\n"), []byte("1\nword\n")); err == nil {
+		t.Error("Parse() error = nil, want unsupported encoding")
+	}
+}
@@ -0,0 +1,134 @@
+package hunspell
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Suggest returns up to limit corrections of a misspelled word, the most likely first.
+// Candidates are single edits: replacements from REP, neighbouring keys from KEY,
+// swapped, missing and extra characters from TRY, and splitting into two words.
+// Words longer than MaxWordLength get no suggestions.
+func (d *Dictionary) Suggest(word string, limit int) []string {
+	if utf8.RuneCountInString(word) > MaxWordLength {
+		return nil
+	}
+
+	s := &suggester{d: d, limit: limit, seen: make(map[string]bool)}
+
+	lower := strings.ToLower(word)
+	runes := []rune(lower)
+
+	for _, rep := range d.rep {
+		for i := strings.Index(lower, rep[0]); i >= 0 && !s.full(); {
+			s.try(lower[:i] + rep[1] + lower[i+len(rep[0]):])
+
+			next := strings.Index(lower[i+1:], rep[0])
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+	}
+
+	for i, r := range runes {
+		for _, row := range d.key {
+			keys := []rune(row)
+			for j, k := range keys {
+				if k != r {
+					continue
+				}
+
+				if j > 0 {
+					s.try(replaceAt(runes, i, keys[j-1]))
+				}
+				if j+1 < len(keys) {
+					s.try(replaceAt(runes, i, keys[j+1]))
+				}
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(runes); i++ {
+		swapped := []rune(lower)
+		swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+		s.try(string(swapped))
+	}
+
+	for i := range runes {
+		s.try(string(runes[:i]) + string(runes[i+1:]))
+	}
+
+	for i := range runes {
+		for _, r := range d.try {
+			if r != runes[i] {
+				s.try(replaceAt(runes, i, r))
+			}
+		}
+	}
+
+	for i := 0; i <= len(runes); i++ {
+		for _, r := range d.try {
+			s.try(string(runes[:i]) + string(r) + string(runes[i:]))
+		}
+	}
+
+	for i := 1; i < len(runes); i++ {
+		s.try(string(runes[:i]) + " " + string(runes[i:]))
+	}
+
+	return restoreCase(word, s.found)
+}
+
+type suggester struct {
+	d     *Dictionary
+	limit int
+	seen  map[string]bool
+	found []string
+}
+
+func (s *suggester) full() bool {
+	return len(s.found) >= s.limit
+}
+
+// try accepts the candidate if every word of it is correct
+func (s *suggester) try(candidate string) {
+	if s.full() || s.seen[candidate] {
+		return
+	}
+	s.seen[candidate] = true
+
+	for _, word := range strings.Fields(candidate) {
+		if !s.d.check(word, true) {
+			return
+		}
+	}
+
+	if strings.TrimSpace(candidate) != "" {
+		s.found = append(s.found, candidate)
+	}
+}
+
+func replaceAt(runes []rune, i int, r rune) string {
+	replaced := []rune(string(runes))
+	replaced[i] = r
+
+	return string(replaced)
+}
+
+// restoreCase applies capitalization of the misspelled word to the suggestions
+func restoreCase(word string, suggestions []string) []string {
+	lower := strings.ToLower(word)
+
+	for i, suggestion := range suggestions {
+		switch {
+		case word == lower:
+		case word == strings.ToUpper(word) && len([]rune(word)) > 1:
+			suggestions[i] = strings.ToUpper(suggestion)
+		case word == title(lower):
+			suggestions[i] = title(suggestion)
+		}
+	}
+
+	return suggestions
+}
@@ -0,0 +1,40 @@
+package hunspell
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	d := load(t, "en")
+
+	tests := []struct {
+		name  string
+		word  string
+		limit int
+		want  []string
+	}{
+		{name: "swapped characters", word: "wrok", limit: 5, want: []string{"work"}},
+		{name: "replacement table", word: "telefone", limit: 5, want: []string{"telephone"}},
+		{name: "neighbouring key", word: "wotk", limit: 5, want: []string{"work"}},
+		{name: "missing character", word: "cites", limit: 5, want: []string{"cities"}},
+		{name: "extra and missing characters", word: "boxe", limit: 5, want: []string{"box", "boxes"}},
+		{name: "limit", word: "boxe", limit: 1, want: []string{"box"}},
+		{name: "two words", word: "helloworld", limit: 5, want: []string{"hello world"}},
+		{name: "capitalized", word: "Wrok", limit: 5, want: []string{"Work"}},
+		{name: "upper case", word: "WROK", limit: 5, want: []string{"WORK"}},
+		{name: "no suggest", word: "damm", limit: 5, want: nil},
+		{name: "forbidden", word: "colur", limit: 5, want: nil},
+		{name: "too long word", word: strings.Repeat("a", MaxWordLength-3) + "wrok", limit: 5, want: nil},
+		{name: "too long multibyte word", word: strings.Repeat("ё", MaxWordLength+1), limit: 5, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Suggest(tt.word, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q, %d) = %q, want %q", tt.word, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package keyring
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// KeySize is the size of master and data keys (AES-256).
+const KeySize = 32
+
+var (
+	ErrUnknownVersion = errors.New("unknown master key version")
+	ErrInvalidKey     = errors.New("invalid key")
+	ErrNoKeys         = errors.New("no master keys configured")
+	ErrCiphertext     = errors.New("ciphertext too short")
+)
+
+// Keyring holds versioned master keys. Data keys are always wrapped with
+// the current (highest) version, older versions are kept only to unwrap
+// data keys that were not rotated yet.
+type Keyring struct {
+	keys    map[int][]byte
+	current int
+}
+
+// New creates a keyring from the versioned master keys.
+func New(keys map[int][]byte) (*Keyring, error) {
+	const op = "lib.keyring.New"
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, ErrNoKeys)
+	}
+
+	kr := &Keyring{keys: make(map[int][]byte, len(keys))}
+	for version, key := range keys {
+		if len(key) != KeySize || version <= 0 {
+			return nil, fmt.Errorf("%s: version %d: %w", op, version, ErrInvalidKey)
+		}
+
+		kr.keys[version] = key
+		if version > kr.current {
+			kr.current = version
+		}
+	}
+
+	return kr, nil
+}
+
+// Load builds a keyring from a base64 master key with its version and/or a key file.
+// Every non-empty line of the key file has the form "<version>:<base64 key>",
+// lines starting with # are ignored.
+func Load(masterKey string, version int, keyFile string) (*Keyring, error) {
+	const op = "lib.keyring.Load"
+
+	keys := make(map[int][]byte)
+
+	if masterKey != "" {
+		key, err := base64.StdEncoding.DecodeString(masterKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		keys[version] = key
+	}
+
+	if keyFile != "" {
+		f, err := os.Open(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+
+			v, k, found := strings.Cut(text, ":")
+			if !found {
+				return nil, fmt.Errorf("%s: line %d: %w", op, line, ErrInvalidKey)
+			}
+
+			ver, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+			}
+
+			key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k))
+			if err != nil {
+				return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+			}
+
+			keys[ver] = key
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return New(keys)
+}
+
+// Current returns the version of the master key used for wrapping.
+func (kr *Keyring) Current() int {
+	return kr.current
+}
+
+// NewDataKey generates a random data key.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Wrap encrypts the data key with the current master key.
+func (kr *Keyring) Wrap(dataKey, aad []byte) (int, []byte, error) {
+	wrapped, err := Seal(kr.keys[kr.current], dataKey, aad)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return kr.current, wrapped, nil
+}
+
+// Unwrap decrypts the data key wrapped with the given master key version.
+func (kr *Keyring) Unwrap(version int, wrapped, aad []byte) ([]byte, error) {
+	key, ok := kr.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("version %d: %w", version, ErrUnknownVersion)
+	}
+
+	return Open(key, wrapped, aad)
+}
+
+// Seal encrypts plaintext with AES-256-GCM. The random nonce is prepended to the result.
+func Seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open decrypts data produced by Seal.
+func Open(key, ciphertext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrCiphertext
+	}
+
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, data, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
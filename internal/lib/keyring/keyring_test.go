@@ -0,0 +1,163 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func encodedKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(testKey(b))
+}
+
+func TestWrapUnwrap(t *testing.T) {
+	old, err := New(map[int][]byte{1: testKey(1)})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	kr, err := New(map[int][]byte{1: testKey(1), 2: testKey(2)})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if kr.Current() != 2 {
+		t.Errorf("Current() = %d, want 2", kr.Current())
+	}
+
+	dataKey, err := NewDataKey()
+	if err != nil {
+		t.Fatalf("NewDataKey() error = %v", err)
+	}
+	aad := []byte("user:1")
+
+	version, wrapped, err := kr.Wrap(dataKey, aad)
+	if err != nil {
+		t.Fatalf("Wrap() error = %v", err)
+	}
+	if version != 2 {
+		t.Errorf("Wrap() version = %d, want the current version 2", version)
+	}
+
+	got, err := kr.Unwrap(version, wrapped, aad)
+	if err != nil || !bytes.Equal(got, dataKey) {
+		t.Errorf("Unwrap() = %x, %v, want %x", got, err, dataKey)
+	}
+
+	// data keys wrapped before the rotation are unwrapped with the older version
+	version, wrapped, err = old.Wrap(dataKey, aad)
+	if err != nil {
+		t.Fatalf("Wrap() with the old keyring error = %v", err)
+	}
+
+	got, err = kr.Unwrap(version, wrapped, aad)
+	if err != nil || !bytes.Equal(got, dataKey) {
+		t.Errorf("Unwrap() of version %d = %x, %v, want %x", version, got, err, dataKey)
+	}
+
+	if _, err := kr.Unwrap(version, wrapped, []byte("user:2")); err == nil {
+		t.Error("Unwrap() with wrong AAD succeeded")
+	}
+
+	if _, err := kr.Unwrap(2, wrapped, aad); err == nil {
+		t.Error("Unwrap() with wrong version succeeded")
+	}
+
+	if _, err := kr.Unwrap(3, wrapped, aad); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Unwrap() of unknown version error = %v, want %v", err, ErrUnknownVersion)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		keys map[int][]byte
+		want error
+	}{
+		{name: "no keys", keys: nil, want: ErrNoKeys},
+		{name: "short key", keys: map[int][]byte{1: testKey(1)[:16]}, want: ErrInvalidKey},
+		{name: "zero version", keys: map[int][]byte{0: testKey(1)}, want: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.keys); !errors.Is(err, tt.want) {
+				t.Errorf("New() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys")
+	content := "# rotated keys\n\n1:" + encodedKey(1) + "\n 2 : " + encodedKey(2) + " \n"
+	if err := os.WriteFile(keyFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	kr, err := Load(encodedKey(3), 3, keyFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if kr.Current() != 3 {
+		t.Errorf("Current() = %d, want 3", kr.Current())
+	}
+	for version := 1; version <= 3; version++ {
+		if !bytes.Equal(kr.keys[version], testKey(byte(version))) {
+			t.Errorf("key of version %d is not loaded", version)
+		}
+	}
+
+	kr, err = Load("", 0, keyFile)
+	if err != nil || kr.Current() != 2 {
+		t.Errorf("Load() without master key = %v, want current version 2", err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "no version", content: encodedKey(1)},
+		{name: "bad version", content: "v1:" + encodedKey(1)},
+		{name: "bad key", content: "1:not base64"},
+		{name: "short key", content: "1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFile := filepath.Join(t.TempDir(), "keys")
+			if err := os.WriteFile(keyFile, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			if _, err := Load("", 0, keyFile); err == nil {
+				t.Errorf("Load(%q) succeeded", tt.content)
+			}
+		})
+	}
+
+	if _, err := Load("", 0, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+
+	if _, err := Load("", 0, ""); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Load() without keys error = %v, want %v", err, ErrNoKeys)
+	}
+}
+
+func TestOpenShortCiphertext(t *testing.T) {
+	if _, err := Open(testKey(1), []byte("short"), nil); !errors.Is(err, ErrCiphertext) {
+		t.Errorf("Open() error = %v, want %v", err, ErrCiphertext)
+	}
+}
+
@@ -0,0 +1,81 @@
+// Package ratelimit limits requests of every key, e.g. a user, with a token bucket
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// cleanupInterval is how often buckets refilled up to the burst are dropped
+const cleanupInterval = time.Minute
+
+type Limiter struct {
+	// rate is the number of tokens added per second
+	rate  float64
+	burst float64
+
+	mu          sync.Mutex
+	buckets     map[int64]*bucket
+	lastCleanup time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// New allows perMinute requests of a key on average and up to burst requests at once.
+// perMinute 0 means unlimited.
+func New(perMinute, burst int) *Limiter {
+	return &Limiter{
+		rate:        float64(perMinute) / 60,
+		burst:       float64(max(burst, 1)),
+		buckets:     make(map[int64]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes a token of the key. Without tokens it returns false and the time until the next one.
+func (l *Limiter) Allow(key int64) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: l.burst, updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*l.rate)
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// cleanup drops buckets which are full again, they are recreated on demand
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < cleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.updatedAt) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
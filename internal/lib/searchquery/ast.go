@@ -0,0 +1,85 @@
+// Package searchquery parses the note search syntax:
+//
+//	tag:work -tag:done created:>2024-01-01 "exact phrase" OR draft
+//
+// Terms separated by spaces (or AND) must all match, OR binds weaker than AND,
+// a leading - or NOT negates a term and parentheses group terms.
+package searchquery
+
+import (
+	"fmt"
+	"time"
+)
+
+// Fields supported in name:value terms
+const (
+	FieldTag      = "tag"
+	FieldNotebook = "notebook"
+	FieldPinned   = "pinned"
+	FieldCreated  = "created"
+	FieldUpdated  = "updated"
+)
+
+// Op compares a date field with the value
+type Op string
+
+const (
+	OpEq  Op = "="
+	OpGt  Op = ">"
+	OpGte Op = ">="
+	OpLt  Op = "<"
+	OpLte Op = "<="
+)
+
+// DateLayout is the format of date values, dates are days in UTC
+const DateLayout = "2006-01-02"
+
+// Node is an element of the parsed query
+type Node interface {
+	node()
+}
+
+// And matches notes matching all nodes
+type And struct {
+	Nodes []Node
+}
+
+// Or matches notes matching any of the nodes
+type Or struct {
+	Nodes []Node
+}
+
+// Not matches notes not matching the node
+type Not struct {
+	Node Node
+}
+
+// Text matches notes containing the words of the value, a phrase must occur as is
+type Text struct {
+	Value  string
+	Phrase bool
+}
+
+// Field matches notes by an attribute. Date is set for created and updated fields.
+type Field struct {
+	Name  string
+	Op    Op
+	Value string
+	Date  time.Time
+}
+
+func (And) node()   {}
+func (Or) node()    {}
+func (Not) node()   {}
+func (Text) node()  {}
+func (Field) node() {}
+
+// Error is a syntax error at the position in runes from the start of the query
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
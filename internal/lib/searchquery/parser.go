@@ -0,0 +1,344 @@
+package searchquery
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// MaxLength limits the query length in runes
+	MaxLength = 1000
+	// maxDepth limits nesting of parentheses and negations
+	maxDepth = 32
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenPhrase
+	tokenField
+	tokenLParen
+	tokenRParen
+	tokenMinus
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type token struct {
+	kind  tokenKind
+	pos   int
+	text  string
+	name  string // field name of tokenField
+	value string // field value of tokenField
+	// valuePos is the position of the field value
+	valuePos int
+}
+
+// Parse builds the syntax tree of the query
+func Parse(query string) (Node, error) {
+	input := []rune(query)
+	if len(input) > MaxLength {
+		return nil, &Error{Pos: MaxLength, Msg: "query is too long"}
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, &Error{Pos: 0, Msg: "empty query"}
+	}
+
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &Error{Pos: tok.pos, Msg: "unexpected " + describe(tok)}
+	}
+
+	return node, nil
+}
+
+func lex(input []rune) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		r := input[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: i, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: i, text: ")"})
+			i++
+		case r == '-':
+			tokens = append(tokens, token{kind: tokenMinus, pos: i, text: "-"})
+			i++
+		case r == '"':
+			value, end, err := lexQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{kind: tokenPhrase, pos: i, text: value})
+			i = end
+		default:
+			start := i
+			for i < len(input) && !isDelimiter(input[i]) {
+				i++
+			}
+			word := string(input[start:i])
+
+			name, value, found := strings.Cut(word, ":")
+			if !found || name == "" {
+				tokens = append(tokens, token{kind: keyword(word), pos: start, text: word})
+				continue
+			}
+
+			tok := token{kind: tokenField, pos: start, text: word, name: strings.ToLower(name), value: value,
+				valuePos: start + len([]rune(name)) + 1}
+
+			// tag:"two words"
+			if value == "" && i < len(input) && input[i] == '"' {
+				quoted, end, err := lexQuoted(input, i)
+				if err != nil {
+					return nil, err
+				}
+
+				tok.value = quoted
+				i = end
+			}
+
+			tokens = append(tokens, tok)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// lexQuoted reads a quoted string starting at input[start] returning it with the position after the closing quote
+func lexQuoted(input []rune, start int) (string, int, error) {
+	for i := start + 1; i < len(input); i++ {
+		if input[i] == '"' {
+			value := string(input[start+1 : i])
+			if strings.TrimSpace(value) == "" {
+				return "", 0, &Error{Pos: start, Msg: "empty phrase"}
+			}
+
+			return value, i + 1, nil
+		}
+	}
+
+	return "", 0, &Error{Pos: start, Msg: "unterminated phrase"}
+}
+
+func isDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+func keyword(word string) tokenKind {
+	switch word {
+	case "AND":
+		return tokenAnd
+	case "OR":
+		return tokenOr
+	case "NOT":
+		return tokenNot
+	default:
+		return tokenWord
+	}
+}
+
+func describe(tok token) string {
+	if tok.kind == tokenEOF {
+		return "end of query"
+	}
+
+	return `"` + tok.text + `"`
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) parseOr(depth int) (Node, error) {
+	node, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{node}
+	for p.peek().kind == tokenOr {
+		p.advance()
+
+		node, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+
+	return Or{Nodes: nodes}, nil
+}
+
+func (p *parser) parseAnd(depth int) (Node, error) {
+	var nodes []Node
+
+	for {
+		tok := p.peek()
+		if tok.kind == tokenEOF || tok.kind == tokenRParen || tok.kind == tokenOr {
+			if len(nodes) == 0 {
+				return nil, &Error{Pos: tok.pos, Msg: "expected term before " + describe(tok)}
+			}
+
+			break
+		}
+
+		if tok.kind == tokenAnd {
+			if len(nodes) == 0 {
+				return nil, &Error{Pos: tok.pos, Msg: "expected term before " + describe(tok)}
+			}
+
+			p.advance()
+			if next := p.peek(); next.kind == tokenEOF || next.kind == tokenRParen || next.kind == tokenOr || next.kind == tokenAnd {
+				return nil, &Error{Pos: next.pos, Msg: "expected term after AND"}
+			}
+		}
+
+		node, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+
+	return And{Nodes: nodes}, nil
+}
+
+func (p *parser) parseUnary(depth int) (Node, error) {
+	tok := p.peek()
+	if tok.kind != tokenMinus && tok.kind != tokenNot {
+		return p.parsePrimary(depth)
+	}
+
+	if depth >= maxDepth {
+		return nil, &Error{Pos: tok.pos, Msg: "query is nested too deeply"}
+	}
+
+	p.advance()
+	if next := p.peek(); next.kind == tokenEOF || next.kind == tokenRParen || next.kind == tokenOr || next.kind == tokenAnd {
+		return nil, &Error{Pos: next.pos, Msg: "expected term after " + describe(tok)}
+	}
+
+	node, err := p.parseUnary(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return Not{Node: node}, nil
+}
+
+func (p *parser) parsePrimary(depth int) (Node, error) {
+	tok := p.advance()
+
+	switch tok.kind {
+	case tokenLParen:
+		if depth >= maxDepth {
+			return nil, &Error{Pos: tok.pos, Msg: "query is nested too deeply"}
+		}
+
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if next := p.peek(); next.kind != tokenRParen {
+			return nil, &Error{Pos: next.pos, Msg: "expected \")\" instead of " + describe(next)}
+		}
+		p.advance()
+
+		return node, nil
+	case tokenWord:
+		return Text{Value: tok.text}, nil
+	case tokenPhrase:
+		return Text{Value: tok.text, Phrase: true}, nil
+	case tokenField:
+		return parseField(tok)
+	default:
+		return nil, &Error{Pos: tok.pos, Msg: "unexpected " + describe(tok)}
+	}
+}
+
+func parseField(tok token) (Node, error) {
+	field := Field{Name: tok.name, Op: OpEq, Value: tok.value}
+
+	if field.Value == "" {
+		return nil, &Error{Pos: tok.valuePos, Msg: "expected value of " + tok.name}
+	}
+
+	switch field.Name {
+	case FieldTag, FieldNotebook:
+		return field, nil
+	case FieldPinned:
+		if field.Value != "true" && field.Value != "false" {
+			return nil, &Error{Pos: tok.valuePos, Msg: "pinned must be true or false"}
+		}
+
+		return field, nil
+	case FieldCreated, FieldUpdated:
+		pos := tok.valuePos
+		for _, op := range []Op{OpGte, OpLte, OpGt, OpLt, OpEq} {
+			if value, found := strings.CutPrefix(field.Value, string(op)); found {
+				field.Op = op
+				field.Value = value
+				pos += len(op)
+				break
+			}
+		}
+
+		date, err := time.Parse(DateLayout, field.Value)
+		if err != nil {
+			return nil, &Error{Pos: pos, Msg: "expected date in format YYYY-MM-DD"}
+		}
+		field.Date = date
+
+		return field, nil
+	default:
+		return nil, &Error{Pos: tok.pos, Msg: "unknown field " + tok.name}
+	}
+}
@@ -0,0 +1,157 @@
+package searchquery
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Node
+	}{
+		{
+			name:  "word",
+			query: "draft",
+			want:  Text{Value: "draft"},
+		},
+		{
+			name:  "phrase",
+			query: `"exact phrase"`,
+			want:  Text{Value: "exact phrase", Phrase: true},
+		},
+		{
+			name:  "implicit and",
+			query: "tag:work -tag:done",
+			want: And{Nodes: []Node{
+				Field{Name: FieldTag, Op: OpEq, Value: "work"},
+				Not{Node: Field{Name: FieldTag, Op: OpEq, Value: "done"}},
+			}},
+		},
+		{
+			name:  "or binds weaker than and",
+			query: `tag:work created:>2024-01-01 "exact phrase" OR draft`,
+			want: Or{Nodes: []Node{
+				And{Nodes: []Node{
+					Field{Name: FieldTag, Op: OpEq, Value: "work"},
+					Field{Name: FieldCreated, Op: OpGt, Value: "2024-01-01", Date: date("2024-01-01")},
+					Text{Value: "exact phrase", Phrase: true},
+				}},
+				Text{Value: "draft"},
+			}},
+		},
+		{
+			name:  "explicit and",
+			query: "a AND b",
+			want:  And{Nodes: []Node{Text{Value: "a"}, Text{Value: "b"}}},
+		},
+		{
+			name:  "not keyword",
+			query: "NOT draft",
+			want:  Not{Node: Text{Value: "draft"}},
+		},
+		{
+			name:  "parentheses",
+			query: "(a OR b) c",
+			want: And{Nodes: []Node{
+				Or{Nodes: []Node{Text{Value: "a"}, Text{Value: "b"}}},
+				Text{Value: "c"},
+			}},
+		},
+		{
+			name:  "quoted field value",
+			query: `tag:"two words"`,
+			want:  Field{Name: FieldTag, Op: OpEq, Value: "two words"},
+		},
+		{
+			name:  "field name is case insensitive",
+			query: "Pinned:true",
+			want:  Field{Name: FieldPinned, Op: OpEq, Value: "true"},
+		},
+		{
+			name:  "date operators",
+			query: "updated:<=2024-02-29 created:2024-01-01",
+			want: And{Nodes: []Node{
+				Field{Name: FieldUpdated, Op: OpLte, Value: "2024-02-29", Date: date("2024-02-29")},
+				Field{Name: FieldCreated, Op: OpEq, Value: "2024-01-01", Date: date("2024-01-01")},
+			}},
+		},
+		{
+			name:  "lowercase keywords are words",
+			query: "this or that",
+			want:  And{Nodes: []Node{Text{Value: "this"}, Text{Value: "or"}, Text{Value: "that"}}},
+		},
+		{
+			name:  "colon without name is a word",
+			query: ":value",
+			want:  Text{Value: ":value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		pos   int
+		msg   string
+	}{
+		{name: "empty", query: "   ", pos: 0, msg: "empty query"},
+		{name: "unterminated phrase", query: `tag:work "open`, pos: 9, msg: "unterminated phrase"},
+		{name: "empty phrase", query: `a "  "`, pos: 2, msg: "empty phrase"},
+		{name: "missing value", query: "tag: work", pos: 4, msg: "expected value of tag"},
+		{name: "unknown field", query: "a color:red", pos: 2, msg: "unknown field color"},
+		{name: "invalid pinned", query: "pinned:yes", pos: 7, msg: "pinned must be true or false"},
+		{name: "invalid date", query: "created:>=2024-13-01", pos: 10, msg: "expected date in format YYYY-MM-DD"},
+		{name: "dangling or", query: "a OR", pos: 4, msg: `expected term before end of query`},
+		{name: "leading or", query: "OR a", pos: 0, msg: `expected term before "OR"`},
+		{name: "dangling and", query: "a AND", pos: 5, msg: "expected term after AND"},
+		{name: "dangling minus", query: "a -", pos: 3, msg: `expected term after "-"`},
+		{name: "unclosed parenthesis", query: "(a b", pos: 4, msg: `expected ")" instead of end of query`},
+		{name: "unexpected parenthesis", query: "a)", pos: 1, msg: `unexpected ")"`},
+		{name: "positions count runes", query: "заметка цвет:red", pos: 8, msg: "unknown field цвет"},
+		{name: "too deep", query: strings.Repeat("(", maxDepth+1) + "a", pos: maxDepth, msg: "query is nested too deeply"},
+		{name: "too long", query: strings.Repeat("a", MaxLength+1), pos: MaxLength, msg: "query is too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+
+			var queryErr *Error
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.query, err)
+			}
+
+			if queryErr.Pos != tt.pos || queryErr.Msg != tt.msg {
+				t.Errorf("Parse(%q) error = %d %q, want %d %q", tt.query, queryErr.Pos, queryErr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package simhash
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize is the number of words in a feature
+const shingleSize = 3
+
+// Fingerprint computes the 64-bit SimHash of the text over lowercase word shingles.
+// Texts that differ only slightly have fingerprints with a small Hamming distance.
+func Fingerprint(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	var features []string
+	if len(words) < shingleSize {
+		features = words
+	} else {
+		for i := 0; i+shingleSize <= len(words); i++ {
+			features = append(features, strings.Join(words[i:i+shingleSize], " "))
+		}
+	}
+
+	var weights [64]int
+	for _, feature := range features {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+
+	return fingerprint
+}
+
+// Distance is the number of differing bits of two fingerprints
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity maps the distance to [0, 1], 1 means equal fingerprints
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(Distance(a, b))/64
+}
+
+// Bands splits the fingerprint into 4 16-bit bands. Fingerprints within
+// distance 3 share at least one band, which allows bucketing candidates.
+func Bands(fingerprint uint64) [4]uint64 {
+	var bands [4]uint64
+	for i := range bands {
+		// band index is kept in the high bits so equal values of different bands don't collide
+		bands[i] = uint64(i)<<16 | (fingerprint>>(16*i))&0xffff
+	}
+
+	return bands
+}
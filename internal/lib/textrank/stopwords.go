@@ -0,0 +1,26 @@
+package textrank
+
+var stopWords = toSet(
+	// english
+	"the", "and", "for", "are", "but", "not", "you", "all", "any", "can", "had", "her", "was", "one", "our", "out",
+	"has", "have", "him", "his", "how", "its", "may", "new", "now", "old", "see", "two", "who", "did", "get", "let",
+	"she", "too", "use", "that", "this", "with", "from", "they", "will", "would", "there", "their", "what", "about",
+	"which", "when", "make", "like", "than", "then", "them", "these", "been", "were", "into", "some", "could", "also",
+	"only", "other", "more", "very", "just", "over", "such", "your", "after", "should", "where", "while", "because",
+	// russian
+	"что", "это", "как", "так", "все", "она", "они", "оно", "его", "её", "мне", "меня", "нас", "вас", "вам", "нам",
+	"был", "была", "было", "были", "быть", "есть", "для", "при", "над", "под", "без", "или", "если", "еще", "ещё",
+	"уже", "только", "когда", "чтобы", "тоже", "также", "там", "тут", "где", "кто", "чем", "тем", "этот", "эта",
+	"эти", "того", "этого", "этой", "этих", "тот", "той", "вот", "даже", "может", "будет", "можно", "нужно",
+	"потому", "после", "перед", "через", "между", "очень", "себя", "свой", "своя", "свои", "который", "которая",
+	"которые", "которых", "всё", "всех", "всего", "ним", "ней", "них", "него", "нее", "неё", "наш", "ваш",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+
+	return set
+}
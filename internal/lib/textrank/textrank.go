@@ -0,0 +1,270 @@
+// Package textrank extracts summaries and keywords from note texts without external services.
+package textrank
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	damping       = 0.85
+	maxIterations = 50
+	convergence   = 1e-4
+
+	// minWordLength drops short words that are mostly prepositions and particles
+	minWordLength = 3
+)
+
+type Keyword struct {
+	Term  string
+	Score float64
+}
+
+// Sentences splits the text on sentence punctuation and line breaks
+func Sentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			sentences = append(sentences, s)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '\n' {
+			flush()
+			continue
+		}
+
+		current.WriteRune(r)
+
+		// a run of terminators ends the sentence if followed by a space or the end of text
+		if isTerminator(r) && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			flush()
+		}
+	}
+	flush()
+
+	return sentences
+}
+
+// Words returns lowercase words of the text without stop words
+func Words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	words := fields[:0]
+	for _, w := range fields {
+		if utf8.RuneCountInString(w) >= minWordLength && !stopWords[w] {
+			words = append(words, w)
+		}
+	}
+
+	return words
+}
+
+// Summarize returns up to n most central sentences of the text in their original order
+func Summarize(text string, n int) []string {
+	sentences := Sentences(text)
+	if len(sentences) <= n {
+		return sentences
+	}
+
+	words := make([][]string, len(sentences))
+	for i, s := range sentences {
+		words[i] = Words(s)
+	}
+
+	scores := rank(similarityMatrix(words))
+
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	top := order[:n]
+	sort.Ints(top)
+
+	summary := make([]string, 0, n)
+	for _, i := range top {
+		summary = append(summary, sentences[i])
+	}
+
+	return summary
+}
+
+// Headline is the most central sentence cut to maxRunes
+func Headline(text string, maxRunes int) string {
+	summary := Summarize(text, 1)
+	if len(summary) == 0 {
+		return ""
+	}
+
+	runes := []rune(summary[0])
+	if len(runes) <= maxRunes {
+		return summary[0]
+	}
+
+	return strings.TrimSpace(string(runes[:maxRunes-1])) + "…"
+}
+
+// TermCounts counts words of the text
+func TermCounts(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range Words(text) {
+		counts[w]++
+	}
+
+	return counts
+}
+
+// Keywords ranks terms of a document by TF-IDF. docFreq is the number of corpus
+// documents containing the term and docs is the size of the corpus.
+func Keywords(counts map[string]int, docFreq map[string]int, docs int, k int) []Keyword {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+
+	keywords := make([]Keyword, 0, len(counts))
+	for term, c := range counts {
+		tf := float64(c) / float64(total)
+
+		keywords = append(keywords, Keyword{Term: term, Score: tf * idf(docFreq[term], docs)})
+	}
+
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+
+		return keywords[i].Term < keywords[j].Term
+	})
+
+	if len(keywords) > k {
+		keywords = keywords[:k]
+	}
+
+	return keywords
+}
+
+// TermWeights builds the tf-idf vector of a document normalized to unit length,
+// so the dot product of two vectors is their cosine similarity
+func TermWeights(counts map[string]int, docFreq map[string]int, docs int) map[string]float64 {
+	weights := make(map[string]float64, len(counts))
+
+	norm := 0.0
+	for term, c := range counts {
+		w := (1 + math.Log(float64(c))) * idf(docFreq[term], docs)
+		weights[term] = w
+		norm += w * w
+	}
+
+	norm = math.Sqrt(norm)
+	for term := range weights {
+		weights[term] /= norm
+	}
+
+	return weights
+}
+
+// idf is smoothed, so terms present in every document keep a small weight
+func idf(docFreq, docs int) float64 {
+	return math.Log(float64(1+docs)/float64(1+docFreq)) + 1
+}
+
+// similarityMatrix weights sentence pairs by shared words normalized by sentence lengths
+func similarityMatrix(words [][]string) [][]float64 {
+	sets := make([]map[string]bool, len(words))
+	for i, ws := range words {
+		sets[i] = make(map[string]bool, len(ws))
+		for _, w := range ws {
+			sets[i][w] = true
+		}
+	}
+
+	m := make([][]float64, len(words))
+	for i := range m {
+		m[i] = make([]float64, len(words))
+	}
+
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			common := 0
+			for w := range sets[i] {
+				if sets[j][w] {
+					common++
+				}
+			}
+
+			if common == 0 {
+				continue
+			}
+
+			norm := math.Log(float64(len(sets[i]))) + math.Log(float64(len(sets[j])))
+			if norm <= 0 {
+				norm = 1
+			}
+
+			m[i][j] = float64(common) / norm
+			m[j][i] = m[i][j]
+		}
+	}
+
+	return m
+}
+
+// rank runs PageRank over the weighted sentence graph
+func rank(m [][]float64) []float64 {
+	n := len(m)
+
+	outWeight := make([]float64, n)
+	for i := range m {
+		for _, w := range m[i] {
+			outWeight[i] += w
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+
+	for iter := 0; iter < maxIterations; iter++ {
+		next := make([]float64, n)
+		delta := 0.0
+
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				if m[j][i] > 0 {
+					sum += m[j][i] / outWeight[j] * scores[j]
+				}
+			}
+
+			next[i] = (1 - damping) + damping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+
+		scores = next
+		if delta < convergence {
+			break
+		}
+	}
+
+	return scores
+}
+
+func isTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
@@ -0,0 +1,68 @@
+package noteservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/auth"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+)
+
+type ActivityManager interface {
+	SaveActivity(ctx context.Context, activity models.NoteActivity) error
+	GetActivity(ctx context.Context, ownerID, noteID int64) ([]models.NoteActivity, error)
+	NoteOwnedBy(ctx context.Context, userID, noteID int64) (bool, error)
+}
+
+// recordActivity appends an action to the note trail. The client is taken from
+// the request context. The action has already happened, so failures are only logged.
+func (ns *NoteService) recordActivity(ctx context.Context, log *slog.Logger, activity models.NoteActivity) {
+	client, _ := ctx.Value(auth.CtxClient).(models.Client)
+
+	activity.ClientIP = client.IP
+	activity.UserAgent = client.UserAgent
+	activity.CreatedAt = time.Now()
+
+	if err := ns.activityManager.SaveActivity(ctx, activity); err != nil {
+		log.Error("failed to record activity", slog.String("action", activity.Action), sl.Err(err))
+	}
+}
+
+// GetActivity returns the trail of the user note, it is available after the note is deleted.
+// The trail is stored per owner, so an empty trail is only returned for the owned note.
+func (ns *NoteService) GetActivity(ctx context.Context, userID, noteID int64) ([]models.NoteActivity, error) {
+	const op = "services.NoteService.GetActivity"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get activity")
+
+	activity, err := ns.activityManager.GetActivity(ctx, userID, noteID)
+	if err != nil {
+		log.Error("failed to get activity", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(activity) == 0 {
+		owned, err := ns.activityManager.NoteOwnedBy(ctx, userID, noteID)
+		if err != nil {
+			log.Error("failed to check note owner", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !owned {
+			log.Warn("note not found")
+
+			return nil, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+	}
+
+	log.Info("activity got successfully")
+
+	return activity, nil
+}
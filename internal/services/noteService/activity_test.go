@@ -0,0 +1,28 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func TestGetActivityWithoutActions(t *testing.T) {
+	s := &fakeStorage{notes: []models.Note{{ID: 1, UserID: 1, Note: "text"}}}
+	ns := newTestService(s)
+
+	activity, err := ns.GetActivity(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("GetActivity() error = %v", err)
+	}
+
+	if activity == nil || len(activity) != 0 {
+		t.Errorf("GetActivity() = %v, want empty trail", activity)
+	}
+
+	_, err = ns.GetActivity(context.Background(), 2, 1)
+	if !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("GetActivity() of another user error = %v, want %v", err, ErrNoteNotFound)
+	}
+}
@@ -0,0 +1,142 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var ErrSpellingErrorNotFound = errors.New("spelling error not found")
+
+type SpellingManager interface {
+	SaveSpellingErrors(ctx context.Context, userID, noteID, revisionID int64, annotations []models.SpellingAnnotation) error
+	GetSpellingErrors(ctx context.Context, userID, noteID, revisionID int64) ([]models.SpellingAnnotation, error)
+	DismissSpellingError(ctx context.Context, userID, noteID, errorID int64) error
+}
+
+// GetSpellingErrors returns open spelling errors of the current note text, shared notes included
+func (ns *NoteService) GetSpellingErrors(ctx context.Context, userID, noteID int64) ([]models.SpellingAnnotation, error) {
+	const op = "services.NoteService.GetSpellingErrors"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get spelling errors")
+
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	annotations, err := ns.spellingManager.GetSpellingErrors(ctx, ownerID, noteID, 0)
+	if err != nil {
+		log.Error("failed to get spelling errors", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("spelling errors got successfully")
+
+	return openAnnotations(annotations), nil
+}
+
+// DismissSpellingError hides the error of the current note text, an error of the same word
+// stays dismissed after the note is edited
+func (ns *NoteService) DismissSpellingError(ctx context.Context, userID, noteID, errorID int64) error {
+	const op = "services.NoteService.DismissSpellingError"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to dismiss spelling error")
+
+	if err := ns.checkOwner(ctx, log, userID, noteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := ns.spellingManager.DismissSpellingError(ctx, userID, noteID, errorID)
+	if err != nil {
+		if errors.Is(err, storage.ErrSpellingErrorNotFound) {
+			log.Warn("spelling error not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSpellingErrorNotFound)
+		}
+
+		log.Error("failed to dismiss spelling error", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("spelling error dismissed successfully")
+
+	return nil
+}
+
+// saveSpellingErrors replaces stored errors of the current note text, failures are logged.
+// Errors of a word dismissed before are dismissed again.
+func (ns *NoteService) saveSpellingErrors(ctx context.Context, log *slog.Logger, userID, noteID int64, spellingErrors []models.SpellError) {
+	previous, err := ns.spellingManager.GetSpellingErrors(ctx, userID, noteID, 0)
+	if err != nil {
+		log.Error("failed to get spelling errors", sl.Err(err))
+	}
+
+	dismissed := make(map[string]bool)
+	for _, annotation := range previous {
+		if annotation.Dismissed {
+			dismissed[annotationKey(annotation.SpellError)] = true
+		}
+	}
+
+	annotations := newAnnotations(spellingErrors)
+	for i := range annotations {
+		annotations[i].Dismissed = dismissed[annotationKey(annotations[i].SpellError)]
+	}
+
+	if err := ns.spellingManager.SaveSpellingErrors(ctx, userID, noteID, 0, annotations); err != nil {
+		log.Error("failed to save spelling errors", sl.Err(err))
+	}
+}
+
+// saveRevisionSpellingErrors keeps errors of the text saved as the revision, failures are logged
+func (ns *NoteService) saveRevisionSpellingErrors(ctx context.Context, log *slog.Logger, userID, noteID, revisionID int64,
+	annotations []models.SpellingAnnotation) {
+	// the revision was not saved, 0 would replace errors of the current text
+	if revisionID == 0 {
+		return
+	}
+
+	if err := ns.spellingManager.SaveSpellingErrors(ctx, userID, noteID, revisionID, annotations); err != nil {
+		log.Error("failed to save spelling errors", sl.Err(err))
+	}
+}
+
+// newAnnotations wraps errors of a checked text
+func newAnnotations(spellingErrors []models.SpellError) []models.SpellingAnnotation {
+	annotations := make([]models.SpellingAnnotation, len(spellingErrors))
+	for i, spellError := range spellingErrors {
+		annotations[i] = models.SpellingAnnotation{SpellError: spellError}
+	}
+
+	return annotations
+}
+
+// annotationKey identifies errors of the same word in different texts
+func annotationKey(spellError models.SpellError) string {
+	return strconv.Itoa(spellError.Code) + ":" + strings.ToLower(spellError.Word)
+}
+
+func openAnnotations(annotations []models.SpellingAnnotation) []models.SpellingAnnotation {
+	open := []models.SpellingAnnotation{}
+	for _, annotation := range annotations {
+		if !annotation.Dismissed {
+			open = append(open, annotation)
+		}
+	}
+
+	return open
+}
@@ -0,0 +1,133 @@
+package noteservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+)
+
+// Autocorrect applies spelling suggestions to arbitrary text without saving it.
+// Words excluded by the user and by the request are left as they are.
+func (ns *NoteService) Autocorrect(ctx context.Context, userID int64, req models.AutocorrectRequest) (models.AutocorrectResult, error) {
+	const op = "services.NoteService.Autocorrect"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to autocorrect text")
+
+	if allowed, retryAfter := ns.spellcheckLimiter.Allow(userID); !allowed {
+		log.Warn("spellcheck rate limit exceeded")
+
+		return models.AutocorrectResult{}, fmt.Errorf("%s: %w", op, &RateLimitError{RetryAfter: retryAfter})
+	}
+
+	if err := ns.checkNoteLength(req.Text); err != nil {
+		log.Warn("text is too long", sl.Err(err))
+
+		return models.AutocorrectResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	settings, err := ns.settingsManager.GetUserSettings(ctx, userID)
+	if err != nil {
+		log.Error("failed to get settings", sl.Err(err))
+
+		return models.AutocorrectResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	spellingErrors, err := ns.spellcheckText(ctx, log, userID, req.Text)
+	if err != nil {
+		log.Warn("spellchecker is unavailable", sl.Err(err))
+
+		return models.AutocorrectResult{}, fmt.Errorf("%s: %w", op, ErrSpellcheckUnavailable)
+	}
+
+	text, edits, _ := applySuggestions(req.Text, spellingErrors, append(settings.AutocorrectExclude, req.Exclude...))
+
+	log.Info("text autocorrected successfully")
+
+	return models.AutocorrectResult{Text: text, Edits: edits}, nil
+}
+
+// autocorrect applies suggestions to the text of a note being saved and returns the remaining errors.
+// The result is nil when nothing was corrected, failures are logged and leave the text as it is.
+func (ns *NoteService) autocorrect(ctx context.Context, log *slog.Logger, userID int64, text string,
+	spellingErrors []models.SpellError) (*models.AutocorrectResult, []models.SpellError) {
+	settings, err := ns.settingsManager.GetUserSettings(ctx, userID)
+	if err != nil {
+		log.Error("failed to get settings", sl.Err(err))
+
+		return nil, spellingErrors
+	}
+
+	corrected, edits, remaining := applySuggestions(text, spellingErrors, settings.AutocorrectExclude)
+	if len(edits) == 0 {
+		return nil, spellingErrors
+	}
+
+	return &models.AutocorrectResult{Text: corrected, Edits: edits}, remaining
+}
+
+// newRevision keeps the earlier text of the note, it is saved together with the note
+func newRevision(userID int64, text, reason string) *models.NoteRevision {
+	return &models.NoteRevision{
+		UserID:    userID,
+		Note:      text,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
+
+// applySuggestions replaces every misspelled word with its first suggestion, positions are in runes.
+// Errors are taken in order of positions: an error overlapping an earlier one and an error whose word
+// is not at its position are dropped. Excluded words (compared case-insensitively) and errors without
+// a different suggestion are kept, the remaining errors are moved to positions in the corrected text.
+func applySuggestions(text string, spellingErrors []models.SpellError, exclude []string) (string, []models.SpellEdit, []models.SpellError) {
+	excluded := make(map[string]bool, len(exclude))
+	for _, word := range exclude {
+		excluded[strings.ToLower(word)] = true
+	}
+
+	sorted := slices.Clone(spellingErrors)
+	slices.SortStableFunc(sorted, func(a, b models.SpellError) int { return a.Pos - b.Pos })
+
+	runes := []rune(text)
+
+	var b strings.Builder
+	edits := []models.SpellEdit{}
+	remaining := []models.SpellError{}
+
+	// copied runes of the text are written to the result, errors before reserved overlap a taken one
+	copied, reserved, shift := 0, 0, 0
+	for _, spellError := range sorted {
+		end := spellError.Pos + len([]rune(spellError.Word))
+		if spellError.Word == "" || spellError.Pos < reserved || end > len(runes) || string(runes[spellError.Pos:end]) != spellError.Word {
+			continue
+		}
+		reserved = end
+
+		if len(spellError.Suggestions) == 0 || spellError.Suggestions[0] == spellError.Word || excluded[strings.ToLower(spellError.Word)] {
+			spellError.Pos += shift
+			remaining = append(remaining, spellError)
+			continue
+		}
+
+		replacement := spellError.Suggestions[0]
+
+		b.WriteString(string(runes[copied:spellError.Pos]))
+		b.WriteString(replacement)
+		copied = end
+
+		edits = append(edits, models.SpellEdit{Pos: spellError.Pos, Word: spellError.Word, Replacement: replacement})
+		shift += len([]rune(replacement)) - len([]rune(spellError.Word))
+	}
+
+	b.WriteString(string(runes[copied:]))
+
+	return b.String(), edits, remaining
+}
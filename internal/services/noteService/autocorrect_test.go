@@ -0,0 +1,105 @@
+package noteservice
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func TestApplySuggestions(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		errors    []models.SpellError
+		exclude   []string
+		want      string
+		edits     []models.SpellEdit
+		remaining []models.SpellError
+	}{
+		{
+			name:   "first suggestion",
+			text:   "I hav a dog",
+			errors: []models.SpellError{{Pos: 2, Word: "hav", Suggestions: []string{"have", "had"}}},
+			want:   "I have a dog",
+			edits:  []models.SpellEdit{{Pos: 2, Word: "hav", Replacement: "have"}},
+		},
+		{
+			name: "kept errors are shifted",
+			text: "I hav a dgo",
+			errors: []models.SpellError{
+				{Pos: 8, Word: "dgo"},
+				{Pos: 2, Word: "hav", Suggestions: []string{"have"}},
+			},
+			want:      "I have a dgo",
+			edits:     []models.SpellEdit{{Pos: 2, Word: "hav", Replacement: "have"}},
+			remaining: []models.SpellError{{Pos: 9, Word: "dgo"}},
+		},
+		{
+			name: "cyrillic and non-BMP runes",
+			text: "😀 ща буду",
+			errors: []models.SpellError{
+				{Pos: 2, Word: "ща", Suggestions: []string{"сейчас"}},
+				{Pos: 5, Word: "буду", Suggestions: []string{"буду"}},
+			},
+			want:      "😀 сейчас буду",
+			edits:     []models.SpellEdit{{Pos: 2, Word: "ща", Replacement: "сейчас"}},
+			remaining: []models.SpellError{{Pos: 9, Word: "буду", Suggestions: []string{"буду"}}},
+		},
+		{
+			name: "overlapping error is dropped",
+			text: "recieve it",
+			errors: []models.SpellError{
+				{Pos: 3, Word: "ieve", Suggestions: []string{"eve"}},
+				{Pos: 0, Word: "recieve", Suggestions: []string{"receive"}},
+			},
+			want:  "receive it",
+			edits: []models.SpellEdit{{Pos: 0, Word: "recieve", Replacement: "receive"}},
+		},
+		{
+			name: "stale positions are dropped",
+			text: "I hav a dog",
+			errors: []models.SpellError{
+				{Pos: -1, Word: "I", Suggestions: []string{"A"}},
+				{Pos: 3, Word: "hav", Suggestions: []string{"have"}},
+				{Pos: 9, Word: "dog", Suggestions: []string{"cat"}},
+				{Pos: 20, Word: "cat", Suggestions: []string{"dog"}},
+			},
+			want: "I hav a dog",
+		},
+		{
+			name: "excluded words are kept",
+			text: "Teh clustr",
+			errors: []models.SpellError{
+				{Pos: 0, Word: "Teh", Suggestions: []string{"The"}},
+				{Pos: 4, Word: "clustr", Suggestions: []string{"cluster"}},
+			},
+			exclude:   []string{"teh"},
+			want:      "Teh cluster",
+			edits:     []models.SpellEdit{{Pos: 4, Word: "clustr", Replacement: "cluster"}},
+			remaining: []models.SpellError{{Pos: 0, Word: "Teh", Suggestions: []string{"The"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.edits == nil {
+				tt.edits = []models.SpellEdit{}
+			}
+			if tt.remaining == nil {
+				tt.remaining = []models.SpellError{}
+			}
+
+			got, edits, remaining := applySuggestions(tt.text, tt.errors, tt.exclude)
+			if got != tt.want {
+				t.Errorf("applySuggestions() text = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(edits, tt.edits) {
+				t.Errorf("applySuggestions() edits = %+v, want %+v", edits, tt.edits)
+			}
+			if !reflect.DeepEqual(remaining, tt.remaining) {
+				t.Errorf("applySuggestions() remaining = %+v, want %+v", remaining, tt.remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrInvalidAnchor   = errors.New("anchor is out of the note text")
+)
+
+type CommentsManager interface {
+	SaveComment(ctx context.Context, ownerID int64, comment models.Comment) (commentID int64, err error)
+	UpdateComment(ctx context.Context, ownerID int64, comment models.Comment) error
+	SetCommentResolved(ctx context.Context, noteID, commentID, userID int64, resolved bool) error
+	DeleteComment(ctx context.Context, noteID, commentID int64) error
+	GetComment(ctx context.Context, ownerID, noteID, commentID int64) (models.Comment, error)
+	GetComments(ctx context.Context, ownerID, noteID int64) ([]models.Comment, error)
+}
+
+// AddComment starts a thread or replies to a comment of the note the user has access to
+func (ns *NoteService) AddComment(ctx context.Context, userID, noteID int64, req models.CommentRequest) (models.Comment, error) {
+	const op = "services.NoteService.AddComment"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to add comment")
+
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	comment := models.Comment{
+		NoteID:    noteID,
+		ParentID:  req.ParentID,
+		AuthorID:  userID,
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+	}
+
+	if req.ParentID != 0 {
+		if _, err := ns.getComment(ctx, log, ownerID, noteID, req.ParentID); err != nil {
+			return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if req.Anchor != nil {
+		note, err := ns.notesManager.GetNote(ctx, ownerID, noteID)
+		if err != nil {
+			log.Error("failed to get note", sl.Err(err))
+
+			return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		text := []rune(note.Note)
+		if req.Anchor.End > len(text) {
+			log.Warn("invalid anchor")
+
+			return models.Comment{}, fmt.Errorf("%s: %w", op, ErrInvalidAnchor)
+		}
+
+		comment.Anchor = &models.Anchor{
+			Start: req.Anchor.Start,
+			End:   req.Anchor.End,
+			Text:  string(text[req.Anchor.Start:req.Anchor.End]),
+		}
+	}
+
+	comment.ID, err = ns.commentsManager.SaveComment(ctx, ownerID, comment)
+	if err != nil {
+		log.Error("failed to save comment", sl.Err(err))
+
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.notifyMentions(ctx, log, userID, noteID, comment.ID, "", comment.Body)
+
+	log.Info("comment added successfully")
+
+	return comment, nil
+}
+
+// GetComments returns threads of the note, replies are nested into their parents
+func (ns *NoteService) GetComments(ctx context.Context, userID, noteID int64) ([]models.Comment, error) {
+	const op = "services.NoteService.GetComments"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get comments")
+
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	comments, err := ns.commentsManager.GetComments(ctx, ownerID, noteID)
+	if err != nil {
+		log.Error("failed to get comments", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("comments got successfully")
+
+	return buildThreads(comments), nil
+}
+
+// UpdateComment changes the body of the comment, only its author can do it
+func (ns *NoteService) UpdateComment(ctx context.Context, userID, noteID, commentID int64, body string) error {
+	const op = "services.NoteService.UpdateComment"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to update comment")
+
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	comment, err := ns.getComment(ctx, log, ownerID, noteID, commentID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if comment.AuthorID != userID {
+		log.Warn("user is not the comment author")
+
+		return fmt.Errorf("%s: %w", op, ErrForbidden)
+	}
+
+	now := time.Now()
+	oldBody := comment.Body
+	comment.Body = body
+	comment.UpdatedAt = &now
+
+	err = ns.commentsManager.UpdateComment(ctx, ownerID, comment)
+	if err != nil {
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			log.Warn("comment not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrCommentNotFound)
+		}
+
+		log.Error("failed to update comment", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.notifyMentions(ctx, log, userID, noteID, commentID, oldBody, body)
+
+	log.Info("comment updated successfully")
+
+	return nil
+}
+
+// DeleteComment removes the comment with its replies, it is allowed to the author and the note owner
+func (ns *NoteService) DeleteComment(ctx context.Context, userID, noteID, commentID int64) error {
+	const op = "services.NoteService.DeleteComment"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to delete comment")
+
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	comment, err := ns.getComment(ctx, log, ownerID, noteID, commentID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if comment.AuthorID != userID && ownerID != userID {
+		log.Warn("user is neither the comment author nor the note owner")
+
+		return fmt.Errorf("%s: %w", op, ErrForbidden)
+	}
+
+	err = ns.commentsManager.DeleteComment(ctx, noteID, commentID)
+	if err != nil {
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			log.Warn("comment not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrCommentNotFound)
+		}
+
+		log.Error("failed to delete comment", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("comment deleted successfully")
+
+	return nil
+}
+
+// ResolveComment marks the comment resolved or unresolved, anyone with access to the note can do it
+func (ns *NoteService) ResolveComment(ctx context.Context, userID, noteID, commentID int64, resolved bool) error {
+	const op = "services.NoteService.ResolveComment"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to resolve comment", slog.Bool("resolved", resolved))
+
+	if _, err := ns.noteOwner(ctx, log, userID, noteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := ns.commentsManager.SetCommentResolved(ctx, noteID, commentID, userID, resolved)
+	if err != nil {
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			log.Warn("comment not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrCommentNotFound)
+		}
+
+		log.Error("failed to resolve comment", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("comment resolved successfully")
+
+	return nil
+}
+
+func (ns *NoteService) getComment(ctx context.Context, log *slog.Logger, ownerID, noteID, commentID int64) (models.Comment, error) {
+	comment, err := ns.commentsManager.GetComment(ctx, ownerID, noteID, commentID)
+	if err != nil {
+		if errors.Is(err, storage.ErrCommentNotFound) {
+			log.Warn("comment not found", sl.Err(err))
+
+			return models.Comment{}, ErrCommentNotFound
+		}
+
+		log.Error("failed to get comment", sl.Err(err))
+
+		return models.Comment{}, err
+	}
+
+	return comment, nil
+}
+
+// reanchorComments moves anchors of the note comments to the new text after an edit.
+// The note is already saved, so failures are only logged.
+func (ns *NoteService) reanchorComments(ctx context.Context, log *slog.Logger, ownerID, noteID int64, text string) {
+	comments, err := ns.commentsManager.GetComments(ctx, ownerID, noteID)
+	if err != nil {
+		log.Error("failed to get comments to reanchor", sl.Err(err))
+		return
+	}
+
+	runes := []rune(text)
+	for _, comment := range comments {
+		if comment.Anchor == nil {
+			continue
+		}
+
+		anchor := reanchor(runes, *comment.Anchor)
+		if anchor == *comment.Anchor {
+			continue
+		}
+
+		comment.Anchor = &anchor
+		if err := ns.commentsManager.UpdateComment(ctx, ownerID, comment); err != nil {
+			log.Error("failed to reanchor comment", slog.Int64("comment_id", comment.ID), sl.Err(err))
+		}
+	}
+}
+
+// reanchor finds the occurrence of the anchored text closest to its previous position.
+// The anchor is detached if the text is gone and attached again if it comes back.
+func reanchor(text []rune, anchor models.Anchor) models.Anchor {
+	target := []rune(anchor.Text)
+
+	best := -1
+	for i := 0; len(target) > 0 && i+len(target) <= len(text); i++ {
+		if !slices.Equal(text[i:i+len(target)], target) {
+			continue
+		}
+
+		if best < 0 || abs(i-anchor.Start) < abs(best-anchor.Start) {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		anchor.Detached = true
+		return anchor
+	}
+
+	anchor.Start = best
+	anchor.End = best + len(target)
+	anchor.Detached = false
+
+	return anchor
+}
+
+// buildThreads nests replies into their parents, comments must be ordered by id
+func buildThreads(comments []models.Comment) []models.Comment {
+	replies := make(map[int64][]models.Comment)
+	for _, c := range comments {
+		if c.ParentID != 0 {
+			replies[c.ParentID] = append(replies[c.ParentID], c)
+		}
+	}
+
+	var attach func(c models.Comment) models.Comment
+	attach = func(c models.Comment) models.Comment {
+		for _, reply := range replies[c.ID] {
+			c.Replies = append(c.Replies, attach(reply))
+		}
+
+		return c
+	}
+
+	threads := []models.Comment{}
+	for _, c := range comments {
+		if c.ParentID == 0 {
+			threads = append(threads, attach(c))
+		}
+	}
+
+	return threads
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,150 @@
+package noteservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+// Today is accepted instead of a date and means the current day in the timezone of the user
+const Today = "today"
+
+var (
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrDailyNoteExists = errors.New("daily note for the date already exists")
+)
+
+// GetDailyNote returns the journal note of the day creating it from the template on first access
+func (ns *NoteService) GetDailyNote(ctx context.Context, userID int64, date string) (models.DailyNote, error) {
+	const op = "services.NoteService.GetDailyNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get daily note")
+
+	loc, settings, err := ns.userLocation(ctx, userID)
+	if err != nil {
+		log.Error("failed to get user timezone", sl.Err(err))
+
+		return models.DailyNote{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	day, err := parseDay(date, loc)
+	if err != nil {
+		log.Warn("invalid date", sl.Err(err))
+
+		return models.DailyNote{}, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+	}
+	daily := models.DailyNote{Date: day.Format(models.DateLayout)}
+
+	daily.Note, err = ns.notesManager.GetDailyNote(ctx, userID, daily.Date)
+	if err == nil {
+		log.Info("daily note got successfully")
+
+		return daily, nil
+	}
+
+	if !errors.Is(err, storage.ErrNoteNotFound) {
+		log.Error("failed to get daily note", sl.Err(err))
+
+		return models.DailyNote{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err := ns.saveNewNote(ctx, log, models.Note{
+		Note:      renderDailyTemplate(cmp.Or(settings.DailyTemplate, ns.daily.Template), day),
+		UserID:    userID,
+		Source:    models.SourceDaily,
+		DailyDate: daily.Date,
+		CreatedAt: time.Now(),
+	}, false)
+	if err != nil && !errors.Is(err, storage.ErrDailyNoteExists) {
+		return models.DailyNote{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// the note of the day was created by a concurrent request otherwise
+	daily.Created = err == nil
+
+	if daily.Created {
+		daily.Note, err = ns.notesManager.GetNote(ctx, userID, result.ID)
+	} else {
+		daily.Note, err = ns.notesManager.GetDailyNote(ctx, userID, daily.Date)
+	}
+	if err != nil {
+		log.Error("failed to get created daily note", sl.Err(err))
+
+		return models.DailyNote{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("daily note got successfully")
+
+	return daily, nil
+}
+
+// GetDailyCalendar lists days of the month having journal notes, empty month means the current one
+func (ns *NoteService) GetDailyCalendar(ctx context.Context, userID int64, month string) (models.DailyCalendar, error) {
+	const op = "services.NoteService.GetDailyCalendar"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get daily calendar")
+
+	loc, _, err := ns.userLocation(ctx, userID)
+	if err != nil {
+		log.Error("failed to get user timezone", sl.Err(err))
+
+		return models.DailyCalendar{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if month == "" {
+		month = time.Now().In(loc).Format(models.MonthLayout)
+	}
+
+	start, err := time.Parse(models.MonthLayout, month)
+	if err != nil {
+		log.Warn("invalid month", sl.Err(err))
+
+		return models.DailyCalendar{}, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+	}
+
+	days, err := ns.notesManager.GetDailyEntries(ctx, userID, start.Format(models.DateLayout), start.AddDate(0, 1, 0).Format(models.DateLayout))
+	if err != nil {
+		log.Error("failed to get daily entries", sl.Err(err))
+
+		return models.DailyCalendar{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("daily calendar got successfully")
+
+	return models.DailyCalendar{Month: month, Days: days}, nil
+}
+
+func parseDay(date string, loc *time.Location) (time.Time, error) {
+	if date == Today {
+		now := time.Now().In(loc)
+
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), nil
+	}
+
+	return time.ParseInLocation(models.DateLayout, date, loc)
+}
+
+// renderDailyTemplate replaces the placeholders of a journal note template, an empty result falls back to the date
+func renderDailyTemplate(text string, day time.Time) string {
+	text = strings.NewReplacer(
+		models.DailyDate, day.Format(models.DateLayout),
+		models.DailyWeekday, day.Weekday().String(),
+	).Replace(text)
+
+	if strings.TrimSpace(text) == "" {
+		return day.Format(models.DateLayout)
+	}
+
+	return text
+}
@@ -0,0 +1,63 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func TestGetDailyNoteWithRequiredFields(t *testing.T) {
+	s := &fakeStorage{fields: []models.FieldDefinition{{Name: "status", Type: models.FieldString, Required: true}}}
+	ns := newTestService(s)
+
+	daily, err := ns.GetDailyNote(context.Background(), 1, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDailyNote() error = %v", err)
+	}
+
+	if !daily.Created || daily.Note.Note != "2024-01-01" {
+		t.Errorf("GetDailyNote() = %+v, want created note 2024-01-01", daily)
+	}
+
+	daily, err = ns.GetDailyNote(context.Background(), 1, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetDailyNote() second call error = %v", err)
+	}
+
+	if daily.Created || len(s.notes) != 1 {
+		t.Errorf("GetDailyNote() second call created = %v with %d notes, want existing note", daily.Created, len(s.notes))
+	}
+
+	_, err = ns.CreateNote(context.Background(), models.NoteRequest{Note: "text"}, 1)
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "status" {
+		t.Errorf("CreateNote() error = %v, want required field status", err)
+	}
+}
+
+func TestRenderDailyTemplate(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "placeholders", text: "# {{.Weekday}}, {{.Date}}\n{{.Date}}", want: "# Monday, 2024-01-01\n2024-01-01"},
+		{name: "plain text", text: "## Планы", want: "## Планы"},
+		{name: "actions are kept as text", text: `{{range 5}}{{printf "%9d" 1}}{{end}}`, want: `{{range 5}}{{printf "%9d" 1}}{{end}}`},
+		{name: "empty falls back to the date", text: " \n", want: "2024-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderDailyTemplate(tt.text, day); got != tt.want {
+				t.Errorf("renderDailyTemplate(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
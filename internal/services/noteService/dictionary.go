@@ -0,0 +1,173 @@
+package noteservice
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+// maxDictionaryWords limits the personal dictionary of a user
+const maxDictionaryWords = 10000
+
+var (
+	ErrWordNotFound    = errors.New("word not found in dictionary")
+	ErrInvalidWordList = errors.New("invalid word list")
+)
+
+type DictionaryManager interface {
+	GetDictionary(ctx context.Context, userID int64) ([]string, error)
+	AddDictionaryWords(ctx context.Context, userID int64, words []string) (added int64, err error)
+	DeleteDictionaryWord(ctx context.Context, userID int64, word string) error
+}
+
+func (ns *NoteService) GetDictionary(ctx context.Context, userID int64) ([]string, error) {
+	const op = "services.NoteService.GetDictionary"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get dictionary")
+
+	words, err := ns.dictionaryManager.GetDictionary(ctx, userID)
+	if err != nil {
+		log.Error("failed to get dictionary", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("dictionary got successfully")
+
+	return words, nil
+}
+
+// AddDictionaryWords returns the number of added words, words already in the dictionary are skipped
+func (ns *NoteService) AddDictionaryWords(ctx context.Context, userID int64, words []string) (int64, error) {
+	const op = "services.NoteService.AddDictionaryWords"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to add dictionary words")
+
+	current, err := ns.dictionaryManager.GetDictionary(ctx, userID)
+	if err != nil {
+		log.Error("failed to get dictionary", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if used := int64(len(current) + len(words)); used > maxDictionaryWords {
+		err := &QuotaError{Quota: QuotaDictionaryWords, Limit: maxDictionaryWords, Used: used}
+		log.Warn("quota exceeded", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	added, err := ns.dictionaryManager.AddDictionaryWords(ctx, userID, words)
+	if err != nil {
+		log.Error("failed to add dictionary words", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("dictionary words added successfully")
+
+	return added, nil
+}
+
+// ImportDictionary adds words of a plain list, one word per line
+func (ns *NoteService) ImportDictionary(ctx context.Context, userID int64, list string) (int64, error) {
+	const op = "services.NoteService.ImportDictionary"
+
+	req := models.DictionaryImport{Words: ParseWordList(list)}
+
+	// Validate fields
+	if err := req.Validate(); err != nil {
+		return 0, fmt.Errorf("%s: %w: %w", op, ErrInvalidWordList, err)
+	}
+
+	added, err := ns.AddDictionaryWords(ctx, userID, req.Words)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return added, nil
+}
+
+func (ns *NoteService) DeleteDictionaryWord(ctx context.Context, userID int64, word string) error {
+	const op = "services.NoteService.DeleteDictionaryWord"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to delete dictionary word")
+
+	err := ns.dictionaryManager.DeleteDictionaryWord(ctx, userID, word)
+	if err != nil {
+		if errors.Is(err, storage.ErrWordNotFound) {
+			log.Warn("word not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrWordNotFound)
+		}
+
+		log.Error("failed to delete dictionary word", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("dictionary word deleted successfully")
+
+	return nil
+}
+
+// ParseWordList reads a word per line skipping blank lines
+func ParseWordList(list string) []string {
+	var words []string
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// personalWords returns the dictionary of the user, failures are logged and leave it empty
+func (ns *NoteService) personalWords(ctx context.Context, log *slog.Logger, userID int64) []string {
+	words, err := ns.dictionaryManager.GetDictionary(ctx, userID)
+	if err != nil {
+		log.Error("failed to get dictionary", sl.Err(err))
+	}
+
+	return words
+}
+
+// filterPersonalWords drops unknown and capitalization errors of words in the dictionary
+func filterPersonalWords(spellingErrors []models.SpellError, words []string) []models.SpellError {
+	if len(words) == 0 {
+		return spellingErrors
+	}
+
+	known := make(map[string]bool, len(words))
+	for _, word := range words {
+		known[strings.ToLower(word)] = true
+	}
+
+	filtered := make([]models.SpellError, 0, len(spellingErrors))
+	for _, spellError := range spellingErrors {
+		ignored := spellError.Code == models.SpellErrorUnknownWord || spellError.Code == models.SpellErrorCapitalization
+		if ignored && known[strings.ToLower(spellError.Word)] {
+			continue
+		}
+
+		filtered = append(filtered, spellError)
+	}
+
+	return filtered
+}
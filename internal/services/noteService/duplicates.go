@@ -0,0 +1,188 @@
+package noteservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/simhash"
+)
+
+const (
+	// maxDuplicateDistance is the max Hamming distance between fingerprints of near-duplicates.
+	// It must stay below the number of simhash bands for bucketing to find all pairs.
+	maxDuplicateDistance = 3
+
+	fingerprintsBackfillBatch = 100
+)
+
+// findDuplicates compares the fingerprint with the fingerprints of all user notes
+func (ns *NoteService) findDuplicates(ctx context.Context, userID int64, fingerprint uint64) ([]models.Duplicate, error) {
+	fingerprints, err := ns.notesManager.GetNoteFingerprints(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := []models.Duplicate{}
+	for _, fp := range fingerprints {
+		if simhash.Distance(fp.Hash, fingerprint) <= maxDuplicateDistance {
+			duplicates = append(duplicates, models.Duplicate{
+				ID:         fp.NoteID,
+				Similarity: simhash.Similarity(fp.Hash, fingerprint),
+			})
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+
+	return duplicates, nil
+}
+
+// GetDuplicates groups the user notes into clusters of near-duplicates
+func (ns *NoteService) GetDuplicates(ctx context.Context, userID int64) ([][]models.Note, error) {
+	const op = "services.NoteService.GetDuplicates"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get duplicates")
+
+	fingerprints, err := ns.notesManager.GetNoteFingerprints(ctx, userID)
+	if err != nil {
+		log.Error("failed to get fingerprints", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	clusters := clusterFingerprints(fingerprints)
+
+	var ids []int64
+	for _, cluster := range clusters {
+		ids = append(ids, cluster...)
+	}
+
+	notes, err := ns.notesManager.GetNotesByIds(ctx, userID, ids)
+	if err != nil {
+		log.Error("failed to get notes", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byID := make(map[int64]models.Note, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+
+	result := make([][]models.Note, 0, len(clusters))
+	for _, cluster := range clusters {
+		group := make([]models.Note, 0, len(cluster))
+		for _, id := range cluster {
+			if note, ok := byID[id]; ok {
+				group = append(group, note)
+			}
+		}
+
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+	}
+
+	log.Info("duplicates got successfully", slog.Int("clusters", len(result)))
+
+	return result, nil
+}
+
+// BackfillFingerprints computes fingerprints of notes of the next user saved before they were
+// introduced, until then the notes are not found as duplicates. It returns false when every note has one.
+func (ns *NoteService) BackfillFingerprints(ctx context.Context) (bool, error) {
+	const op = "services.NoteService.BackfillFingerprints"
+
+	log := ns.log.With(slog.String("op", op))
+
+	userIDs, err := ns.notesManager.GetUsersWithoutFingerprints(ctx, 1)
+	if err != nil {
+		log.Error("failed to get users without fingerprints", sl.Err(err))
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(userIDs) == 0 {
+		return false, nil
+	}
+
+	log = log.With(slog.Int64("userID", userIDs[0]))
+
+	log.Info("attempting to backfill fingerprints")
+
+	notes, err := ns.notesManager.GetNotesWithoutFingerprint(ctx, userIDs[0], fingerprintsBackfillBatch)
+	if err != nil {
+		log.Error("failed to get notes without fingerprint", sl.Err(err))
+
+		return true, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, note := range notes {
+		if err := ns.notesManager.SaveFingerprint(ctx, userIDs[0], note.ID, simhash.Fingerprint(note.Note)); err != nil {
+			log.Error("failed to save fingerprint", sl.Err(err))
+
+			return true, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	log.Info("fingerprints backfilled successfully", slog.Int("notes", len(notes)))
+
+	return true, nil
+}
+
+// clusterFingerprints links notes within maxDuplicateDistance and returns connected
+// components of more than one note. Candidates are bucketed by simhash bands,
+// so only notes sharing a band are compared.
+func clusterFingerprints(fingerprints []models.Fingerprint) [][]int64 {
+	parent := make([]int, len(fingerprints))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	buckets := make(map[uint64][]int)
+	for i, fp := range fingerprints {
+		for _, band := range simhash.Bands(fp.Hash) {
+			for _, j := range buckets[band] {
+				if simhash.Distance(fp.Hash, fingerprints[j].Hash) <= maxDuplicateDistance {
+					parent[find(i)] = find(j)
+				}
+			}
+
+			buckets[band] = append(buckets[band], i)
+		}
+	}
+
+	groups := make(map[int][]int64)
+	for i, fp := range fingerprints {
+		root := find(i)
+		groups[root] = append(groups[root], fp.NoteID)
+	}
+
+	clusters := make([][]int64, 0)
+	for _, group := range groups {
+		if len(group) > 1 {
+			sort.Slice(group, func(i, j int) bool { return group[i] < group[j] })
+			clusters = append(clusters, group)
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+
+	return clusters
+}
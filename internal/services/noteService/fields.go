@@ -0,0 +1,229 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var (
+	ErrInvalidFields = errors.New("invalid fields")
+	ErrFieldExists   = errors.New("field already exists")
+	ErrFieldNotFound = errors.New("field not found")
+)
+
+type FieldsManager interface {
+	SaveFieldDefinition(ctx context.Context, userID int64, field models.FieldDefinition) (fieldID int64, err error)
+	GetFieldDefinitions(ctx context.Context, userID int64) ([]models.FieldDefinition, error)
+	DeleteFieldDefinition(ctx context.Context, userID, fieldID int64) error
+}
+
+// FieldError describes a custom field value that doesn't match its definition
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidFields, e.Field, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidFields
+}
+
+func (ns *NoteService) CreateField(ctx context.Context, userID int64, field models.FieldDefinition) (int64, error) {
+	const op = "services.NoteService.CreateField"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to create field")
+
+	id, err := ns.fieldsManager.SaveFieldDefinition(ctx, userID, field)
+	if err != nil {
+		if errors.Is(err, storage.ErrFieldExists) {
+			log.Warn("field already exists", sl.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", op, ErrFieldExists)
+		}
+
+		log.Error("failed to save field", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("field created successfully")
+
+	return id, nil
+}
+
+func (ns *NoteService) GetFields(ctx context.Context, userID int64) ([]models.FieldDefinition, error) {
+	const op = "services.NoteService.GetFields"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get fields")
+
+	fields, err := ns.fieldsManager.GetFieldDefinitions(ctx, userID)
+	if err != nil {
+		log.Error("failed to get fields", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("fields got successfully")
+
+	return fields, nil
+}
+
+func (ns *NoteService) DeleteField(ctx context.Context, userID, fieldID int64) error {
+	const op = "services.NoteService.DeleteField"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to delete field")
+
+	err := ns.fieldsManager.DeleteFieldDefinition(ctx, userID, fieldID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFieldNotFound) {
+			log.Warn("field not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrFieldNotFound)
+		}
+
+		log.Error("failed to delete field", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("field deleted successfully")
+
+	return nil
+}
+
+// validateFields checks note field values against the user definitions. Required fields
+// are not checked for notes created by the service, e.g. daily notes, the user fills them later.
+func (ns *NoteService) validateFields(ctx context.Context, userID int64, values map[string]interface{}, required bool) error {
+	definitions, err := ns.fieldsManager.GetFieldDefinitions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	defined := make(map[string]models.FieldDefinition, len(definitions))
+	for _, def := range definitions {
+		defined[def.Name] = def
+
+		if value, ok := values[def.Name]; required && def.Required && (!ok || value == nil) {
+			return &FieldError{Field: def.Name, Reason: "is required"}
+		}
+	}
+
+	for name, value := range values {
+		def, ok := defined[name]
+		if !ok {
+			return &FieldError{Field: name, Reason: "is not defined"}
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if err := checkFieldValue(def, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkFieldValue(def models.FieldDefinition, value interface{}) error {
+	switch def.Type {
+	case models.FieldNumber:
+		if _, ok := value.(float64); !ok {
+			return &FieldError{Field: def.Name, Reason: "must be a number"}
+		}
+	case models.FieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return &FieldError{Field: def.Name, Reason: "must be a boolean"}
+		}
+	case models.FieldString:
+		if _, ok := value.(string); !ok {
+			return &FieldError{Field: def.Name, Reason: "must be a string"}
+		}
+	case models.FieldDate:
+		s, ok := value.(string)
+		if !ok {
+			return &FieldError{Field: def.Name, Reason: "must be a date"}
+		}
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return &FieldError{Field: def.Name, Reason: "must be a date YYYY-MM-DD"}
+		}
+	case models.FieldEnum:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(def.Enum, s) {
+			return &FieldError{Field: def.Name, Reason: "must be one of the enum values"}
+		}
+	}
+
+	return nil
+}
+
+// typedFieldFilter converts query string values of the filter to JSON types of the definitions,
+// so they can be matched by jsonb containment
+func (ns *NoteService) typedFieldFilter(ctx context.Context, userID int64, filter models.NoteFilter) (models.NoteFilter, error) {
+	if len(filter.Fields) == 0 && filter.SortField == "" {
+		return filter, nil
+	}
+
+	definitions, err := ns.fieldsManager.GetFieldDefinitions(ctx, userID)
+	if err != nil {
+		return models.NoteFilter{}, err
+	}
+
+	defined := make(map[string]models.FieldDefinition, len(definitions))
+	for _, def := range definitions {
+		defined[def.Name] = def
+	}
+
+	if _, ok := defined[filter.SortField]; filter.SortField != "" && !ok {
+		return models.NoteFilter{}, &FieldError{Field: filter.SortField, Reason: "is not defined"}
+	}
+
+	typed := make(map[string]interface{}, len(filter.Fields))
+	for name, value := range filter.Fields {
+		def, ok := defined[name]
+		if !ok {
+			return models.NoteFilter{}, &FieldError{Field: name, Reason: "is not defined"}
+		}
+
+		s, _ := value.(string)
+		switch def.Type {
+		case models.FieldNumber:
+			n, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return models.NoteFilter{}, &FieldError{Field: name, Reason: "must be a number"}
+			}
+			typed[name] = n
+		case models.FieldBoolean:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return models.NoteFilter{}, &FieldError{Field: name, Reason: "must be a boolean"}
+			}
+			typed[name] = b
+		default:
+			typed[name] = s
+		}
+	}
+
+	filter.Fields = typed
+
+	return filter, nil
+}
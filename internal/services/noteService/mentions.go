@@ -0,0 +1,78 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+// mentionRe matches "@user@mail.com" not preceded by a word character, users have no names besides emails
+var mentionRe = regexp.MustCompile(`(?:^|[^\w@.])@([\w.+\-]+@[\w\-]+(?:\.[\w\-]+)+)`)
+
+type Notifier interface {
+	Notify(ctx context.Context, n models.Notification)
+}
+
+// extractMentions returns lowercased emails mentioned in the text without repeats
+func extractMentions(text string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+
+	for _, m := range mentionRe.FindAllStringSubmatch(text, -1) {
+		email := strings.ToLower(strings.TrimRight(m[1], "."))
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+
+	return emails
+}
+
+// notifyMentions notifies users mentioned in the new text but not in the old one.
+// Only users with access to the note are notified, failures are only logged.
+func (ns *NoteService) notifyMentions(ctx context.Context, log *slog.Logger, actorID, noteID, commentID int64, oldText, newText string) {
+	old := make(map[string]bool)
+	for _, email := range extractMentions(oldText) {
+		old[email] = true
+	}
+
+	for _, email := range extractMentions(newText) {
+		if old[email] {
+			continue
+		}
+
+		user, err := ns.sharesManager.UserByEmail(ctx, email)
+		if err != nil {
+			if !errors.Is(err, storage.ErrUserNotFound) {
+				log.Error("failed to get mentioned user", sl.Err(err))
+			}
+			continue
+		}
+
+		if user.ID == actorID {
+			continue
+		}
+
+		if _, err := ns.sharesManager.NoteOwner(ctx, user.ID, noteID); err != nil {
+			if !errors.Is(err, storage.ErrNoteNotFound) {
+				log.Error("failed to check access of mentioned user", sl.Err(err))
+			}
+			continue
+		}
+
+		ns.notifier.Notify(ctx, models.Notification{
+			UserID:    user.ID,
+			Kind:      models.NotificationMention,
+			ActorID:   actorID,
+			NoteID:    noteID,
+			CommentID: commentID,
+		})
+	}
+}
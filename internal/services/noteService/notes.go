@@ -2,92 +2,541 @@ package noteservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/blankspace9/notes-app/internal/config"
 	"github.com/blankspace9/notes-app/internal/domain/models"
 	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/ratelimit"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	"github.com/blankspace9/notes-app/internal/lib/simhash"
+	"github.com/blankspace9/notes-app/internal/lib/textrank"
+	"github.com/blankspace9/notes-app/internal/storage"
 )
 
 const (
 	emptyValue = 0
 )
 
+var ErrNoteNotFound = errors.New("note not found")
+
 type NoteService struct {
-	log          *slog.Logger
-	notesManager NotesManager
-	spellChecker SpellChecker
+	log                   *slog.Logger
+	notesManager          NotesManager
+	rulesManager          RulesManager
+	fieldsManager         FieldsManager
+	activityManager       ActivityManager
+	sharesManager         SharesManager
+	commentsManager       CommentsManager
+	smartFoldersManager   SmartFoldersManager
+	settingsManager       SettingsManager
+	revisionsManager      RevisionsManager
+	dictionaryManager     DictionaryManager
+	spellingManager       SpellingManager
+	spellcheckJobsManager SpellcheckJobsManager
+	notifier              Notifier
+	spellChecker          SpellChecker
+	limits                config.Limits
+	stats                 config.Stats
+	daily                 config.Daily
+	spellcheck            config.SpellChecker
+	spellcheckLimiter     *ratelimit.Limiter
 }
 
 type NotesManager interface {
-	SaveNote(ctx context.Context, note models.Note) (noteID int64, err error)
+	SaveNote(ctx context.Context, note models.Note, revision *models.NoteRevision) (noteID, revisionID int64, err error)
+	UpdateNote(ctx context.Context, note models.Note, revision *models.NoteRevision) (revisionID int64, err error)
+	UpdateNoteAttributes(ctx context.Context, note models.Note) error
+	DeleteNote(ctx context.Context, userID, noteID int64) error
+	RestoreNote(ctx context.Context, userID, noteID int64) error
+	GetNote(ctx context.Context, userID, noteID int64) (models.Note, error)
 	GetNotesByUserId(ctx context.Context, userID int64) ([]models.Note, error)
 	GetNotesPageByUserId(ctx context.Context, userID int64, page, limit int) ([]models.Note, error)
+	FindNotes(ctx context.Context, userID int64, filter models.NoteFilter) ([]models.Note, error)
+	GetDailyNote(ctx context.Context, userID int64, date string) (models.Note, error)
+	GetDailyEntries(ctx context.Context, userID int64, from, to string) ([]models.DailyEntry, error)
+	SearchNotes(ctx context.Context, userID int64, query searchquery.Node, filter models.SearchFilter) ([]models.Note, error)
+	SuggestNotes(ctx context.Context, userID int64, prefix string, limit int) ([]models.Suggestion, error)
+	GetUsage(ctx context.Context, userID int64) (models.Usage, error)
+	GetStats(ctx context.Context, userID int64, timezone string, since time.Time) (models.Stats, error)
+	GetNotesByIds(ctx context.Context, userID int64, ids []int64) ([]models.Note, error)
+	GetNoteFingerprints(ctx context.Context, userID int64) ([]models.Fingerprint, error)
+	GetUsersWithoutFingerprints(ctx context.Context, limit int) ([]int64, error)
+	GetNotesWithoutFingerprint(ctx context.Context, userID int64, limit int) ([]models.Note, error)
+	SaveFingerprint(ctx context.Context, userID, noteID int64, fingerprint uint64) error
+	SaveNoteTerms(ctx context.Context, userID, noteID int64, weights map[string]float64) error
+	DeleteNoteTerms(ctx context.Context, noteID int64) error
+	GetDocumentFrequencies(ctx context.Context, userID int64, terms []string) (docFreq map[string]int, docs int, err error)
+	GetNotesWithoutTerms(ctx context.Context, userID int64, limit int) ([]models.Note, error)
+	GetNotesAfterId(ctx context.Context, userID, afterID int64, limit int) ([]models.Note, error)
+	GetUsersWithoutTerms(ctx context.Context, limit int) ([]int64, error)
+	GetRelatedNotes(ctx context.Context, userID, noteID int64, queryTerms, limit int) ([]models.NoteScore, error)
 }
 
 type SpellChecker interface {
-	CheckSpelling(text string) ([]models.SpellError, error)
+	CheckSpelling(ctx context.Context, text string) ([]models.SpellError, error)
+}
+
+// WordsSpellChecker accepts words of the personal dictionary as correct
+type WordsSpellChecker interface {
+	CheckSpellingWithWords(ctx context.Context, text string, words []string) ([]models.SpellError, error)
+}
+
+// Managers are the storages used by the note service
+type Managers struct {
+	Notes          NotesManager
+	Rules          RulesManager
+	Fields         FieldsManager
+	Activity       ActivityManager
+	Shares         SharesManager
+	Comments       CommentsManager
+	SmartFolders   SmartFoldersManager
+	Settings       SettingsManager
+	Revisions      RevisionsManager
+	Dictionary     DictionaryManager
+	Spelling       SpellingManager
+	SpellcheckJobs SpellcheckJobsManager
 }
 
-func New(log *slog.Logger, notesManager NotesManager, spellChecker SpellChecker) *NoteService {
+func New(log *slog.Logger, managers Managers, notifier Notifier, spellChecker SpellChecker,
+	limits config.Limits, stats config.Stats, daily config.Daily, spellcheck config.SpellChecker) *NoteService {
 	return &NoteService{
-		log:          log,
-		notesManager: notesManager,
-		spellChecker: spellChecker,
+		log:                   log,
+		notesManager:          managers.Notes,
+		rulesManager:          managers.Rules,
+		fieldsManager:         managers.Fields,
+		activityManager:       managers.Activity,
+		sharesManager:         managers.Shares,
+		commentsManager:       managers.Comments,
+		smartFoldersManager:   managers.SmartFolders,
+		settingsManager:       managers.Settings,
+		revisionsManager:      managers.Revisions,
+		dictionaryManager:     managers.Dictionary,
+		spellingManager:       managers.Spelling,
+		spellcheckJobsManager: managers.SpellcheckJobs,
+		notifier:              notifier,
+		spellChecker:          spellChecker,
+		limits:                limits,
+		stats:                 stats,
+		daily:                 daily,
+		spellcheck:            spellcheck,
+		spellcheckLimiter:     ratelimit.New(spellcheck.RateLimit, spellcheck.RateBurst),
 	}
 }
 
-func (ns *NoteService) CreateNote(ctx context.Context, note string, userID int64) (int64, []models.SpellError, error) {
+func (ns *NoteService) CreateNote(ctx context.Context, req models.NoteRequest, userID int64) (models.SaveNoteResult, error) {
 	const op = "services.NoteService.CreateNote"
 
 	log := ns.log.With(slog.String("op", op))
 
 	log.Info("attempting to create note")
 
-	spellingErrors, err := ns.spellChecker.CheckSpelling(note)
+	note := models.Note{
+		Note:      req.Note,
+		UserID:    userID,
+		Tags:      req.Tags,
+		Notebook:  req.Notebook,
+		Pinned:    req.Pinned,
+		Fields:    req.Fields,
+		Source:    models.SourceAPI,
+		CreatedAt: time.Now(),
+	}
+
+	result, err := ns.saveNewNote(ctx, log, note, req.Autocorrect)
 	if err != nil {
-		log.Error("failed to check spelling errors", sl.Err(err))
+		return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	log.Info("note created successfully")
+
+	return result, nil
+}
+
+// saveNewNote checks and saves the note running hooks of a created note, errors are logged.
+// With autocorrect the text before correction is kept as a revision. In async mode the note
+// is checked in background unless it is autocorrected.
+func (ns *NoteService) saveNewNote(ctx context.Context, log *slog.Logger, note models.Note, autocorrect bool) (models.SaveNoteResult, error) {
+	if err := ns.checkNoteLength(note.Note); err != nil {
+		log.Warn("note is too long", sl.Err(err))
+
+		return models.SaveNoteResult{}, err
 	}
 
-	id, err := ns.notesManager.SaveNote(ctx, models.Note{
-		Note:      note,
-		UserID:    userID,
-		CreatedAt: time.Now(),
-	})
+	if err := ns.validateFields(ctx, note.UserID, note.Fields, note.Source != models.SourceDaily); err != nil {
+		if errors.Is(err, ErrInvalidFields) {
+			log.Warn("invalid fields", sl.Err(err))
+		} else {
+			log.Error("failed to validate fields", sl.Err(err))
+		}
+
+		return models.SaveNoteResult{}, err
+	}
+
+	if err := ns.checkQuotas(ctx, note.UserID, 1, int64(len(note.Note))); err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			log.Warn("quota exceeded", sl.Err(err))
+		} else {
+			log.Error("failed to check quotas", sl.Err(err))
+		}
+
+		return models.SaveNoteResult{}, err
+	}
+
+	async := ns.spellcheck.Async && !autocorrect
+
+	spellingErrors, spellcheckStatus := []models.SpellError{}, models.SpellcheckPending
+	if !async {
+		spellingErrors, spellcheckStatus = ns.checkSpelling(ctx, log, note.UserID, note.Note)
+	}
+
+	original, originalErrors := note.Note, spellingErrors
+	var corrected *models.AutocorrectResult
+	if autocorrect && spellcheckStatus == models.SpellcheckOK {
+		corrected, spellingErrors = ns.autocorrect(ctx, log, note.UserID, note.Note, spellingErrors)
+		if corrected != nil {
+			note.Note = corrected.Text
+		}
+	}
+
+	note.Fingerprint = simhash.Fingerprint(note.Note)
+	note.Summary = textrank.Headline(note.Note, headlineLength)
+
+	duplicates, err := ns.findDuplicates(ctx, note.UserID, note.Fingerprint)
 	if err != nil {
-		log.Error("failed to save note", sl.Err(err))
+		log.Error("failed to find duplicates", sl.Err(err))
 
-		return 0, nil, fmt.Errorf("%s: %w", op, err)
+		return models.SaveNoteResult{}, err
 	}
 
-	log.Info("note created successfully")
+	var revision *models.NoteRevision
+	if corrected != nil {
+		revision = newRevision(note.UserID, original, models.RevisionAutocorrect)
+	}
+
+	var revisionID int64
+	note.ID, revisionID, err = ns.notesManager.SaveNote(ctx, note, revision)
+	if err != nil {
+		var limitErr *storage.LimitError
+		if errors.As(err, &limitErr) {
+			err = quotaError(err)
+			log.Warn("quota exceeded", sl.Err(err))
+
+			return models.SaveNoteResult{}, err
+		}
+
+		if errors.Is(err, storage.ErrDailyNoteExists) {
+			log.Warn("daily note already exists", sl.Err(err))
+		} else {
+			log.Error("failed to save note", sl.Err(err))
+		}
 
-	return id, spellingErrors, nil
+		return models.SaveNoteResult{}, err
+	}
+
+	ns.recordActivity(ctx, log, models.NoteActivity{NoteID: note.ID, OwnerID: note.UserID, ActorID: note.UserID, Action: models.ActionCreated})
+	ns.notifyMentions(ctx, log, note.UserID, note.ID, 0, "", note.Note)
+	ns.indexNote(ctx, log, note.UserID, note)
+
+	if async {
+		spellcheckStatus = ns.enqueueSpellcheck(ctx, log, note.UserID, note.ID)
+	}
+
+	if spellcheckStatus == models.SpellcheckOK {
+		ns.saveSpellingErrors(ctx, log, note.UserID, note.ID, spellingErrors)
+	}
+
+	if corrected != nil {
+		corrected.RevisionID = revisionID
+		ns.saveRevisionSpellingErrors(ctx, log, note.UserID, note.ID, revisionID, newAnnotations(originalErrors))
+	}
+
+	return models.SaveNoteResult{
+		ID:                 note.ID,
+		SpellingErrors:     spellingErrors,
+		SpellcheckStatus:   spellcheckStatus,
+		PossibleDuplicates: duplicates,
+		AppliedRules:       ns.applyRules(ctx, log, note),
+		Autocorrect:        corrected,
+	}, nil
 }
 
-func (ns *NoteService) GetNotes(ctx context.Context, userID int64, page, limit int) ([]models.Note, error) {
+func (ns *NoteService) UpdateNote(ctx context.Context, req models.NoteRequest, userID, noteID int64) (models.SaveNoteResult, error) {
+	const op = "services.NoteService.UpdateNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to update note")
+
+	result, _, err := ns.updateNote(ctx, log, req, userID, noteID, "")
+	if err != nil {
+		return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("note updated successfully")
+
+	return result, nil
+}
+
+// updateNote checks and saves the note running hooks of an updated note, errors are logged.
+// With a replaced reason the replaced text is kept as a revision, with autocorrect the text before
+// correction is. The revision is saved together with the note, its id is returned.
+func (ns *NoteService) updateNote(ctx context.Context, log *slog.Logger, req models.NoteRequest, userID, noteID int64,
+	replacedReason string) (models.SaveNoteResult, int64, error) {
+	note, err := ns.notesManager.GetNote(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return models.SaveNoteResult{}, 0, ErrNoteNotFound
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return models.SaveNoteResult{}, 0, err
+	}
+
+	if err := ns.checkNoteLength(req.Note); err != nil {
+		log.Warn("note is too long", sl.Err(err))
+
+		return models.SaveNoteResult{}, 0, err
+	}
+
+	if err := ns.validateFields(ctx, userID, req.Fields, true); err != nil {
+		if errors.Is(err, ErrInvalidFields) {
+			log.Warn("invalid fields", sl.Err(err))
+		} else {
+			log.Error("failed to validate fields", sl.Err(err))
+		}
+
+		return models.SaveNoteResult{}, 0, err
+	}
+
+	if err := ns.checkQuotas(ctx, userID, 0, int64(len(req.Note)-len(note.Note))); err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			log.Warn("quota exceeded", sl.Err(err))
+		} else {
+			log.Error("failed to check quotas", sl.Err(err))
+		}
+
+		return models.SaveNoteResult{}, 0, err
+	}
+
+	async := ns.spellcheck.Async && !req.Autocorrect
+
+	spellingErrors, spellcheckStatus := []models.SpellError{}, models.SpellcheckPending
+	if !async {
+		spellingErrors, spellcheckStatus = ns.checkSpelling(ctx, log, userID, req.Note)
+	}
+	originalErrors := spellingErrors
+
+	var corrected *models.AutocorrectResult
+	if req.Autocorrect && spellcheckStatus == models.SpellcheckOK {
+		corrected, spellingErrors = ns.autocorrect(ctx, log, userID, req.Note, spellingErrors)
+	}
+
+	oldText := note.Note
+
+	note.Note = req.Note
+	if corrected != nil {
+		note.Note = corrected.Text
+	}
+	note.Tags = req.Tags
+	note.Notebook = req.Notebook
+	note.Pinned = req.Pinned
+	note.Fields = req.Fields
+	note.UserID = userID
+	note.Fingerprint = simhash.Fingerprint(note.Note)
+	note.Summary = textrank.Headline(note.Note, headlineLength)
+
+	var revision *models.NoteRevision
+	switch {
+	case corrected != nil:
+		revision = newRevision(userID, req.Note, models.RevisionAutocorrect)
+	case replacedReason != "":
+		revision = newRevision(userID, oldText, replacedReason)
+	}
+
+	revisionID, err := ns.notesManager.UpdateNote(ctx, note, revision)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return models.SaveNoteResult{}, 0, ErrNoteNotFound
+		}
+
+		var limitErr *storage.LimitError
+		if errors.As(err, &limitErr) {
+			log.Warn("quota exceeded", sl.Err(err))
+
+			return models.SaveNoteResult{}, 0, quotaError(err)
+		}
+
+		log.Error("failed to update note", sl.Err(err))
+
+		return models.SaveNoteResult{}, 0, err
+	}
+
+	ns.recordActivity(ctx, log, models.NoteActivity{NoteID: note.ID, OwnerID: userID, ActorID: userID, Action: models.ActionEdited})
+
+	ns.indexNote(ctx, log, userID, note)
+
+	if oldText != note.Note {
+		ns.reanchorComments(ctx, log, userID, note.ID, note.Note)
+		ns.notifyMentions(ctx, log, userID, note.ID, 0, oldText, note.Note)
+	}
+
+	if async {
+		spellcheckStatus = ns.enqueueSpellcheck(ctx, log, userID, note.ID)
+	}
+
+	// errors of the replaced text are stale, they are dropped even if the spellchecker is unavailable.
+	// A pending job replaces them keeping dismissed ones.
+	if spellcheckStatus == models.SpellcheckOK || (spellcheckStatus == models.SpellcheckUnavailable && oldText != note.Note) {
+		ns.saveSpellingErrors(ctx, log, userID, note.ID, spellingErrors)
+	}
+
+	if corrected != nil {
+		corrected.RevisionID = revisionID
+		ns.saveRevisionSpellingErrors(ctx, log, userID, note.ID, revisionID, newAnnotations(originalErrors))
+	}
+
+	return models.SaveNoteResult{
+		ID:               note.ID,
+		SpellingErrors:   spellingErrors,
+		SpellcheckStatus: spellcheckStatus,
+		AppliedRules:     ns.applyRules(ctx, log, note),
+		Autocorrect:      corrected,
+	}, revisionID, nil
+}
+
+// DeleteNote moves the note to trash, it is hidden from listings until restored
+func (ns *NoteService) DeleteNote(ctx context.Context, userID, noteID int64) error {
+	const op = "services.NoteService.DeleteNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to delete note")
+
+	err := ns.notesManager.DeleteNote(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to delete note", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.recordActivity(ctx, log, models.NoteActivity{NoteID: noteID, OwnerID: userID, ActorID: userID, Action: models.ActionDeleted})
+	ns.unindexNote(ctx, log, noteID)
+
+	log.Info("note deleted successfully")
+
+	return nil
+}
+
+func (ns *NoteService) RestoreNote(ctx context.Context, userID, noteID int64) error {
+	const op = "services.NoteService.RestoreNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to restore note")
+
+	err := ns.notesManager.RestoreNote(ctx, userID, noteID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNoteNotFound):
+			log.Warn("deleted note not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		case errors.Is(err, storage.ErrDailyNoteExists):
+			log.Warn("daily note already exists", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrDailyNoteExists)
+		}
+
+		log.Error("failed to restore note", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.recordActivity(ctx, log, models.NoteActivity{NoteID: noteID, OwnerID: userID, ActorID: userID, Action: models.ActionRestored})
+
+	if note, err := ns.notesManager.GetNote(ctx, userID, noteID); err == nil {
+		ns.indexNote(ctx, log, userID, note)
+	} else {
+		log.Error("failed to get restored note", sl.Err(err))
+	}
+
+	log.Info("note restored successfully")
+
+	return nil
+}
+
+func (ns *NoteService) GetNote(ctx context.Context, userID, noteID int64) (models.Note, error) {
+	const op = "services.NoteService.GetNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get note")
+
+	// shared notes are read with the key of their owner
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	note, err := ns.notesManager.GetNote(ctx, ownerID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return models.Note{}, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("note got successfully")
+
+	return note, nil
+}
+
+func (ns *NoteService) GetNotes(ctx context.Context, userID int64, filter models.NoteFilter) ([]models.Note, error) {
 	const op = "services.NoteService.GetNotes"
 
 	log := ns.log.With(slog.String("op", op))
 
 	log.Info("attempting to get notes")
 
-	var notes []models.Note
-	var err error
-	if page == emptyValue || limit == emptyValue {
-		notes, err = ns.notesManager.GetNotesByUserId(ctx, userID)
-	} else {
-		notes, err = ns.notesManager.GetNotesPageByUserId(ctx, userID, page, limit)
+	filter, err := ns.typedFieldFilter(ctx, userID, filter)
+	if err != nil {
+		if errors.Is(err, ErrInvalidFields) {
+			log.Warn("invalid fields filter", sl.Err(err))
+		} else {
+			log.Error("failed to get fields", sl.Err(err))
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+
+	notes, err := ns.notesManager.FindNotes(ctx, userID, filter)
 	if err != nil {
 		log.Error("failed to get notes", sl.Err(err))
 
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	withSummaries(notes, filter.WithSummary)
+
 	log.Info("notes got successfully")
 
 	return notes, nil
@@ -0,0 +1,112 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+const (
+	QuotaNoteLength      = "note_length"
+	QuotaNotes           = "notes"
+	QuotaStorage         = "storage"
+	QuotaDictionaryWords = "dictionary_words"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaError describes which limit a request would exceed
+type QuotaError struct {
+	Quota string
+	Limit int64
+	Used  int64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s limit is %d, used %d", ErrQuotaExceeded, e.Quota, e.Limit, e.Used)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// checkNoteLength is cheap and runs before any external call
+func (ns *NoteService) checkNoteLength(note string) error {
+	if ns.limits.MaxNoteLength == emptyValue {
+		return nil
+	}
+
+	length := utf8.RuneCountInString(note)
+	if length > ns.limits.MaxNoteLength {
+		return &QuotaError{Quota: QuotaNoteLength, Limit: int64(ns.limits.MaxNoteLength), Used: int64(length)}
+	}
+
+	return nil
+}
+
+// checkQuotas verifies that adding the given number of notes and bytes fits into the user limits.
+// It rejects a note before the spellcheck, the storage enforces the limits atomically when saving.
+func (ns *NoteService) checkQuotas(ctx context.Context, userID int64, notes, size int64) error {
+	if ns.limits.MaxNotesPerUser == emptyValue && ns.limits.MaxTotalBytes == emptyValue {
+		return nil
+	}
+
+	usage, err := ns.notesManager.GetUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if ns.limits.MaxNotesPerUser != emptyValue && notes > 0 && usage.Notes+notes > ns.limits.MaxNotesPerUser {
+		return &QuotaError{Quota: QuotaNotes, Limit: ns.limits.MaxNotesPerUser, Used: usage.Notes}
+	}
+
+	if ns.limits.MaxTotalBytes != emptyValue && size > 0 && usage.Bytes+size > ns.limits.MaxTotalBytes {
+		return &QuotaError{Quota: QuotaStorage, Limit: ns.limits.MaxTotalBytes, Used: usage.Bytes}
+	}
+
+	return nil
+}
+
+// quotaError converts a limit enforced by the storage to the quota error, other errors are returned as is
+func quotaError(err error) error {
+	var limitErr *storage.LimitError
+	if !errors.As(err, &limitErr) {
+		return err
+	}
+
+	quota := QuotaNotes
+	if limitErr.Limit == storage.LimitBytes {
+		quota = QuotaStorage
+	}
+
+	return &QuotaError{Quota: quota, Limit: limitErr.Max, Used: limitErr.Used}
+}
+
+func (ns *NoteService) GetUsage(ctx context.Context, userID int64) (models.Usage, error) {
+	const op = "services.NoteService.GetUsage"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get usage")
+
+	usage, err := ns.notesManager.GetUsage(ctx, userID)
+	if err != nil {
+		log.Error("failed to get usage", sl.Err(err))
+
+		return models.Usage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	usage.MaxNotes = ns.limits.MaxNotesPerUser
+	usage.MaxBytes = ns.limits.MaxTotalBytes
+	usage.MaxNoteLength = int64(ns.limits.MaxNoteLength)
+
+	log.Info("usage got successfully")
+
+	return usage, nil
+}
@@ -0,0 +1,189 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/textrank"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+const (
+	// relatedBudget bounds the time of a related notes request
+	relatedBudget = time.Second
+
+	// relatedQueryTerms heaviest terms of the note are matched, they are the most specific ones
+	relatedQueryTerms = 32
+
+	maxRelated         = 50
+	termsBackfillBatch = 100
+
+	// termsBackfillBudget bounds the time spent on notes of one user in background
+	termsBackfillBudget = 10 * time.Second
+)
+
+var ErrBudgetExceeded = errors.New("request took too long")
+
+// GetRelated returns notes of the user most similar to the note by tf-idf cosine similarity
+func (ns *NoteService) GetRelated(ctx context.Context, userID, noteID int64, k int) ([]models.RelatedNote, error) {
+	const op = "services.NoteService.GetRelated"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get related notes")
+
+	k = min(max(k, 1), maxRelated)
+	deadline := time.Now().Add(relatedBudget)
+
+	if _, err := ns.notesManager.GetNote(ctx, userID, noteID); err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	queryCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	scores, err := ns.notesManager.GetRelatedNotes(queryCtx, userID, noteID, relatedQueryTerms, k)
+	if err != nil {
+		if errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+			log.Warn("related notes budget exceeded", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrBudgetExceeded)
+		}
+
+		log.Error("failed to get related notes", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids := make([]int64, 0, len(scores))
+	for _, score := range scores {
+		ids = append(ids, score.NoteID)
+	}
+
+	notes, err := ns.notesManager.GetNotesByIds(ctx, userID, ids)
+	if err != nil {
+		log.Error("failed to get notes", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byID := make(map[int64]models.Note, len(notes))
+	for _, n := range notes {
+		byID[n.ID] = n
+	}
+
+	related := make([]models.RelatedNote, 0, len(scores))
+	for _, score := range scores {
+		if n, ok := byID[score.NoteID]; ok {
+			related = append(related, models.RelatedNote{Note: n, Score: score.Score})
+		}
+	}
+
+	log.Info("related notes got successfully")
+
+	return related, nil
+}
+
+// indexNote updates the term vector of the saved note. Failures are only logged,
+// the note stays unindexed and is picked up by the backfill later.
+func (ns *NoteService) indexNote(ctx context.Context, log *slog.Logger, userID int64, note models.Note) {
+	if err := ns.saveNoteTerms(ctx, userID, note); err != nil {
+		log.Error("failed to index note terms", sl.Err(err))
+	}
+}
+
+// unindexNote removes the note from the term index, failures are only logged
+func (ns *NoteService) unindexNote(ctx context.Context, log *slog.Logger, noteID int64) {
+	if err := ns.notesManager.DeleteNoteTerms(ctx, noteID); err != nil {
+		log.Error("failed to delete note terms", sl.Err(err))
+	}
+}
+
+// saveNoteTerms weights terms of the note against the indexed notes of the user
+func (ns *NoteService) saveNoteTerms(ctx context.Context, userID int64, note models.Note) error {
+	counts := textrank.TermCounts(note.Note)
+
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+
+	docFreq, docs, err := ns.notesManager.GetDocumentFrequencies(ctx, userID, terms)
+	if err != nil {
+		return err
+	}
+
+	return ns.notesManager.SaveNoteTerms(ctx, userID, note.ID, textrank.TermWeights(counts, docFreq, docs))
+}
+
+// BackfillTerms indexes old notes of the next user having them, so their words are searchable
+// without waiting for a request of the user. It returns false when every note is indexed.
+func (ns *NoteService) BackfillTerms(ctx context.Context) (bool, error) {
+	const op = "services.NoteService.BackfillTerms"
+
+	log := ns.log.With(slog.String("op", op))
+
+	userIDs, err := ns.notesManager.GetUsersWithoutTerms(ctx, 1)
+	if err != nil {
+		log.Error("failed to get users without terms", sl.Err(err))
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(userIDs) == 0 {
+		return false, nil
+	}
+
+	log = log.With(slog.Int64("userID", userIDs[0]))
+
+	log.Info("attempting to backfill note terms")
+
+	if err := ns.backfillTerms(ctx, log, userIDs[0], time.Now().Add(termsBackfillBudget)); err != nil {
+		return true, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("note terms backfilled successfully")
+
+	return true, nil
+}
+
+// backfillTerms indexes notes saved before the index existed or whose indexing failed until the deadline
+func (ns *NoteService) backfillTerms(ctx context.Context, log *slog.Logger, userID int64, until time.Time) error {
+	for time.Now().Before(until) {
+		notes, err := ns.notesManager.GetNotesWithoutTerms(ctx, userID, termsBackfillBatch)
+		if err != nil {
+			log.Error("failed to get notes without terms", sl.Err(err))
+			return err
+		}
+
+		for _, note := range notes {
+			if !time.Now().Before(until) {
+				return nil
+			}
+
+			if err := ns.saveNoteTerms(ctx, userID, note); err != nil {
+				log.Error("failed to backfill note terms", sl.Err(err))
+				return err
+			}
+		}
+
+		if len(notes) < termsBackfillBatch {
+			return nil
+		}
+	}
+
+	return nil
+}
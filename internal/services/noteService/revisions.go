@@ -0,0 +1,120 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type RevisionsManager interface {
+	GetRevisions(ctx context.Context, userID, noteID int64) ([]models.NoteRevision, error)
+	GetRevision(ctx context.Context, userID, noteID, revisionID int64) (models.NoteRevision, error)
+}
+
+// GetRevisions returns revisions of the note, withSpelling includes spelling errors of every revision
+func (ns *NoteService) GetRevisions(ctx context.Context, userID, noteID int64, withSpelling bool) ([]models.NoteRevision, error) {
+	const op = "services.NoteService.GetRevisions"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get revisions")
+
+	if _, err := ns.notesManager.GetNote(ctx, userID, noteID); err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	revisions, err := ns.revisionsManager.GetRevisions(ctx, userID, noteID)
+	if err != nil {
+		log.Error("failed to get revisions", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if withSpelling {
+		for i := range revisions {
+			revisions[i].SpellingErrors, err = ns.spellingManager.GetSpellingErrors(ctx, userID, noteID, revisions[i].ID)
+			if err != nil {
+				log.Error("failed to get spelling errors", sl.Err(err))
+
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	log.Info("revisions got successfully")
+
+	return revisions, nil
+}
+
+// RestoreRevision replaces the text of the note with the revision, the replaced text becomes a revision too
+func (ns *NoteService) RestoreRevision(ctx context.Context, userID, noteID, revisionID int64) (models.SaveNoteResult, error) {
+	const op = "services.NoteService.RestoreRevision"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to restore revision")
+
+	revision, err := ns.revisionsManager.GetRevision(ctx, userID, noteID, revisionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrRevisionNotFound) {
+			log.Warn("revision not found", sl.Err(err))
+
+			return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, ErrRevisionNotFound)
+		}
+
+		log.Error("failed to get revision", sl.Err(err))
+
+		return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	note, err := ns.notesManager.GetNote(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// errors of the replaced text are replaced by the update, they are kept with its revision
+	replacedErrors, err := ns.spellingManager.GetSpellingErrors(ctx, userID, noteID, 0)
+	if err != nil {
+		log.Error("failed to get spelling errors", sl.Err(err))
+	}
+
+	result, replacedID, err := ns.updateNote(ctx, log, models.NoteRequest{
+		Note:     revision.Note,
+		Tags:     note.Tags,
+		Notebook: note.Notebook,
+		Pinned:   note.Pinned,
+		Fields:   note.Fields,
+	}, userID, noteID, models.RevisionRestore)
+	if err != nil {
+		return models.SaveNoteResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.saveRevisionSpellingErrors(ctx, log, userID, noteID, replacedID, replacedErrors)
+
+	log.Info("revision restored successfully")
+
+	return result, nil
+}
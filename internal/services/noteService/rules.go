@@ -0,0 +1,487 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+const (
+	// rulesPageSize is the number of notes processed at once by dry runs and jobs
+	rulesPageSize = 100
+
+	// ruleJobLease bounds processing of a page of a job, then another worker may continue the job
+	ruleJobLease = time.Minute
+)
+
+var (
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrJobNotFound  = errors.New("job not found")
+)
+
+type RulesManager interface {
+	SaveRule(ctx context.Context, userID int64, rule models.Rule) (ruleID int64, err error)
+	UpdateRule(ctx context.Context, userID int64, rule models.Rule) error
+	DeleteRule(ctx context.Context, userID, ruleID int64) error
+	GetRule(ctx context.Context, userID, ruleID int64) (models.Rule, error)
+	GetRules(ctx context.Context, userID int64) ([]models.Rule, error)
+	SaveRuleJob(ctx context.Context, userID int64, job models.RuleJob) (jobID int64, err error)
+	ClaimRuleJob(ctx context.Context, now, lockedUntil time.Time) (models.RuleJob, error)
+	UpdateRuleJob(ctx context.Context, job models.RuleJob) error
+	GetRuleJob(ctx context.Context, userID, jobID int64) (models.RuleJob, error)
+}
+
+// compiledRule keeps the compiled regex of a rule evaluated against many notes
+type compiledRule struct {
+	models.Rule
+	re *regexp.Regexp
+}
+
+func compileRule(rule models.Rule) (compiledRule, error) {
+	cr := compiledRule{Rule: rule}
+
+	if rule.Conditions.Regex != "" {
+		re, err := regexp.Compile(rule.Conditions.Regex)
+		if err != nil {
+			return compiledRule{}, err
+		}
+
+		cr.re = re
+	}
+
+	return cr, nil
+}
+
+// match reports whether the note satisfies all conditions of the rule
+func (cr compiledRule) match(note models.Note) bool {
+	c := cr.Conditions
+
+	if len(c.Keywords) > 0 {
+		text := strings.ToLower(note.Note)
+		found := false
+		for _, keyword := range c.Keywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if cr.re != nil && !cr.re.MatchString(note.Note) {
+		return false
+	}
+
+	length := utf8.RuneCountInString(note.Note)
+	if c.MinLength > 0 && length < c.MinLength {
+		return false
+	}
+	if c.MaxLength > 0 && length > c.MaxLength {
+		return false
+	}
+
+	if len(c.Sources) > 0 && !slices.Contains(c.Sources, note.Source) {
+		return false
+	}
+
+	return true
+}
+
+// apply changes tags, notebook and pin state of the note and reports whether anything changed
+func (cr compiledRule) apply(note *models.Note) bool {
+	a := cr.Actions
+	changed := false
+
+	for _, tag := range a.Tags {
+		if !slices.Contains(note.Tags, tag) {
+			note.Tags = append(note.Tags, tag)
+			changed = true
+		}
+	}
+
+	if a.Notebook != "" && note.Notebook != a.Notebook {
+		note.Notebook = a.Notebook
+		changed = true
+	}
+
+	if a.Pin && !note.Pinned {
+		note.Pinned = true
+		changed = true
+	}
+
+	return changed
+}
+
+// applyRules evaluates enabled rules of the user against the saved note and returns ids of
+// rules that changed it. The note is already saved, so failures are only logged.
+func (ns *NoteService) applyRules(ctx context.Context, log *slog.Logger, note models.Note) []int64 {
+	rules, err := ns.rulesManager.GetRules(ctx, note.UserID)
+	if err != nil {
+		log.Error("failed to get rules", sl.Err(err))
+
+		return nil
+	}
+
+	var applied []int64
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		cr, err := compileRule(rule)
+		if err != nil {
+			log.Warn("invalid rule", slog.Int64("ruleID", rule.ID), sl.Err(err))
+			continue
+		}
+
+		if cr.match(note) && cr.apply(&note) {
+			applied = append(applied, rule.ID)
+		}
+	}
+
+	if len(applied) == 0 {
+		return nil
+	}
+
+	if err := ns.notesManager.UpdateNoteAttributes(ctx, note); err != nil {
+		log.Error("failed to apply rules", sl.Err(err))
+
+		return nil
+	}
+
+	return applied
+}
+
+func (ns *NoteService) CreateRule(ctx context.Context, userID int64, req models.RuleRequest) (int64, error) {
+	const op = "services.NoteService.CreateRule"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to create rule")
+
+	id, err := ns.rulesManager.SaveRule(ctx, userID, ruleFromRequest(req))
+	if err != nil {
+		log.Error("failed to save rule", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rule created successfully")
+
+	return id, nil
+}
+
+func (ns *NoteService) UpdateRule(ctx context.Context, userID, ruleID int64, req models.RuleRequest) error {
+	const op = "services.NoteService.UpdateRule"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to update rule")
+
+	rule := ruleFromRequest(req)
+	rule.ID = ruleID
+
+	err := ns.rulesManager.UpdateRule(ctx, userID, rule)
+	if err != nil {
+		if errors.Is(err, storage.ErrRuleNotFound) {
+			log.Warn("rule not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrRuleNotFound)
+		}
+
+		log.Error("failed to update rule", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rule updated successfully")
+
+	return nil
+}
+
+func (ns *NoteService) DeleteRule(ctx context.Context, userID, ruleID int64) error {
+	const op = "services.NoteService.DeleteRule"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to delete rule")
+
+	err := ns.rulesManager.DeleteRule(ctx, userID, ruleID)
+	if err != nil {
+		if errors.Is(err, storage.ErrRuleNotFound) {
+			log.Warn("rule not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrRuleNotFound)
+		}
+
+		log.Error("failed to delete rule", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rule deleted successfully")
+
+	return nil
+}
+
+func (ns *NoteService) GetRules(ctx context.Context, userID int64) ([]models.Rule, error) {
+	const op = "services.NoteService.GetRules"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get rules")
+
+	rules, err := ns.rulesManager.GetRules(ctx, userID)
+	if err != nil {
+		log.Error("failed to get rules", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rules got successfully")
+
+	return rules, nil
+}
+
+// DryRunRule returns existing notes that the rule would change, without changing them
+func (ns *NoteService) DryRunRule(ctx context.Context, userID int64, req models.RuleRequest) ([]models.Note, error) {
+	const op = "services.NoteService.DryRunRule"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to dry run rule")
+
+	cr, err := compileRule(ruleFromRequest(req))
+	if err != nil {
+		log.Warn("invalid rule", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	affected := []models.Note{}
+	err = ns.forEachNote(ctx, userID, func(note models.Note) error {
+		if cr.match(note) && cr.apply(&note) {
+			affected = append(affected, note)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error("failed to get notes", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rule dry run completed", slog.Int("affected", len(affected)))
+
+	return affected, nil
+}
+
+// ApplyRule queues a background job applying the rule to existing notes
+func (ns *NoteService) ApplyRule(ctx context.Context, userID, ruleID int64) (models.RuleJob, error) {
+	const op = "services.NoteService.ApplyRule"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to apply rule")
+
+	rule, err := ns.rulesManager.GetRule(ctx, userID, ruleID)
+	if err != nil {
+		if errors.Is(err, storage.ErrRuleNotFound) {
+			log.Warn("rule not found", sl.Err(err))
+
+			return models.RuleJob{}, fmt.Errorf("%s: %w", op, ErrRuleNotFound)
+		}
+
+		log.Error("failed to get rule", sl.Err(err))
+
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := compileRule(rule); err != nil {
+		log.Warn("invalid rule", sl.Err(err))
+
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	job := models.RuleJob{
+		RuleID:    ruleID,
+		Status:    models.JobPending,
+		CreatedAt: time.Now(),
+	}
+
+	job.ID, err = ns.rulesManager.SaveRuleJob(ctx, userID, job)
+	if err != nil {
+		log.Error("failed to save job", sl.Err(err))
+
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rule job queued", slog.Int64("jobID", job.ID))
+
+	return job, nil
+}
+
+// ProcessRuleJob applies the rule of the next job to a page of notes, it returns false when no job is due.
+// The current version of the rule is applied, so changes of the rule affect the rest of the notes.
+func (ns *NoteService) ProcessRuleJob(ctx context.Context) (bool, error) {
+	const op = "services.NoteService.ProcessRuleJob"
+
+	log := ns.log.With(slog.String("op", op))
+
+	now := time.Now()
+	job, err := ns.rulesManager.ClaimRuleJob(ctx, now, now.Add(ruleJobLease))
+	if err != nil {
+		if errors.Is(err, storage.ErrNoJobs) {
+			return false, nil
+		}
+
+		log.Error("failed to claim rule job", sl.Err(err))
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("jobID", job.ID))
+
+	log.Info("attempting to run rule job", slog.Int64("afterNoteID", job.LastNoteID))
+
+	done, err := ns.runRuleJob(ctx, &job)
+	if err != nil {
+		log.Error("rule job failed", sl.Err(err))
+
+		job.Status = models.JobFailed
+		job.Error = err.Error()
+		done = true
+	}
+
+	if done {
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		if job.Status != models.JobFailed {
+			job.Status = models.JobDone
+		}
+	}
+
+	if err := ns.rulesManager.UpdateRuleJob(ctx, job); err != nil {
+		log.Error("failed to update job", sl.Err(err))
+
+		return true, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if done {
+		log.Info("rule job finished", slog.String("status", job.Status),
+			slog.Int64("processed", job.Processed), slog.Int64("matched", job.Matched))
+	}
+
+	return true, nil
+}
+
+// runRuleJob applies the rule to the page of notes after the last processed one, it returns true
+// after the last page
+func (ns *NoteService) runRuleJob(ctx context.Context, job *models.RuleJob) (bool, error) {
+	ctx, cancel := context.WithDeadline(ctx, job.LockedUntil)
+	defer cancel()
+
+	rule, err := ns.rulesManager.GetRule(ctx, job.UserID, job.RuleID)
+	if err != nil {
+		return false, err
+	}
+
+	cr, err := compileRule(rule)
+	if err != nil {
+		return false, err
+	}
+
+	notes, err := ns.notesManager.GetNotesAfterId(ctx, job.UserID, job.LastNoteID, rulesPageSize)
+	if err != nil {
+		return false, err
+	}
+
+	for _, note := range notes {
+		if cr.match(note) && cr.apply(&note) {
+			note.UserID = job.UserID
+			if err := ns.notesManager.UpdateNoteAttributes(ctx, note); err != nil {
+				return false, err
+			}
+
+			job.Matched++
+		}
+
+		job.Processed++
+		job.LastNoteID = note.ID
+	}
+
+	return len(notes) < rulesPageSize, nil
+}
+
+func (ns *NoteService) GetRuleJob(ctx context.Context, userID, jobID int64) (models.RuleJob, error) {
+	const op = "services.NoteService.GetRuleJob"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get rule job")
+
+	job, err := ns.rulesManager.GetRuleJob(ctx, userID, jobID)
+	if err != nil {
+		if errors.Is(err, storage.ErrJobNotFound) {
+			log.Warn("job not found", sl.Err(err))
+
+			return models.RuleJob{}, fmt.Errorf("%s: %w", op, ErrJobNotFound)
+		}
+
+		log.Error("failed to get job", sl.Err(err))
+
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("rule job got successfully")
+
+	return job, nil
+}
+
+// forEachNote walks all notes of the user page by page
+func (ns *NoteService) forEachNote(ctx context.Context, userID int64, fn func(note models.Note) error) error {
+	for page := 1; ; page++ {
+		notes, err := ns.notesManager.GetNotesPageByUserId(ctx, userID, page, rulesPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, note := range notes {
+			if err := fn(note); err != nil {
+				return err
+			}
+		}
+
+		if len(notes) < rulesPageSize {
+			return nil
+		}
+	}
+}
+
+func ruleFromRequest(req models.RuleRequest) models.Rule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return models.Rule{
+		Name:       req.Name,
+		Enabled:    enabled,
+		Conditions: req.Conditions,
+		Actions:    req.Actions,
+	}
+}
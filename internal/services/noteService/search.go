@@ -0,0 +1,76 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+)
+
+const (
+	// suggestBudget bounds the time of an autocomplete request, it runs on every keystroke
+	suggestBudget = 200 * time.Millisecond
+
+	maxSuggestions = 20
+)
+
+// SearchNotes finds notes matching the query, syntax errors are returned as *searchquery.Error
+func (ns *NoteService) SearchNotes(ctx context.Context, userID int64, query string, filter models.SearchFilter) ([]models.Note, error) {
+	const op = "services.NoteService.SearchNotes"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to search notes")
+
+	node, err := searchquery.Parse(query)
+	if err != nil {
+		log.Warn("invalid search query", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	notes, err := ns.notesManager.SearchNotes(ctx, userID, node, filter)
+	if err != nil {
+		log.Error("failed to search notes", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("notes searched successfully")
+
+	return notes, nil
+}
+
+// Suggest completes the search prefix with tags, note titles and words
+func (ns *NoteService) Suggest(ctx context.Context, userID int64, prefix string, limit int) ([]models.Suggestion, error) {
+	const op = "services.NoteService.Suggest"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to suggest completions")
+
+	queryCtx, cancel := context.WithTimeout(ctx, suggestBudget)
+	defer cancel()
+
+	suggestions, err := ns.notesManager.SuggestNotes(queryCtx, userID, prefix, min(max(limit, 1), maxSuggestions))
+	if err != nil {
+		if errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+			log.Warn("suggestions budget exceeded", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrBudgetExceeded)
+		}
+
+		log.Error("failed to suggest completions", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("completions suggested successfully")
+
+	return suggestions, nil
+}
@@ -0,0 +1,218 @@
+package noteservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/config"
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+// fakeStorage keeps notes in memory, methods not used by a test panic on the nil embedded managers
+type fakeStorage struct {
+	NotesManager
+	RulesManager
+	FieldsManager
+	ActivityManager
+	SettingsManager
+	SpellcheckJobsManager
+	RevisionsManager
+	SpellingManager
+	CommentsManager
+
+	fields    []models.FieldDefinition
+	notes     []models.Note
+	revisions []models.NoteRevision
+	jobs      []models.SpellcheckJob
+	deadJobs  []models.SpellcheckJob
+}
+
+func (s *fakeStorage) GetUserSettings(ctx context.Context, userID int64) (models.UserSettings, error) {
+	return models.UserSettings{}, nil
+}
+
+func (s *fakeStorage) GetFieldDefinitions(ctx context.Context, userID int64) ([]models.FieldDefinition, error) {
+	return s.fields, nil
+}
+
+func (s *fakeStorage) SaveNote(ctx context.Context, note models.Note, revision *models.NoteRevision) (int64, int64, error) {
+	note.ID = int64(len(s.notes) + 1)
+	s.notes = append(s.notes, note)
+
+	return note.ID, s.saveRevision(note.ID, revision), nil
+}
+
+func (s *fakeStorage) UpdateNote(ctx context.Context, note models.Note, revision *models.NoteRevision) (int64, error) {
+	for i := range s.notes {
+		if s.notes[i].UserID == note.UserID && s.notes[i].ID == note.ID {
+			s.notes[i] = note
+
+			return s.saveRevision(note.ID, revision), nil
+		}
+	}
+
+	return 0, storage.ErrNoteNotFound
+}
+
+func (s *fakeStorage) saveRevision(noteID int64, revision *models.NoteRevision) int64 {
+	if revision == nil {
+		return 0
+	}
+
+	r := *revision
+	r.ID = int64(len(s.revisions) + 1)
+	r.NoteID = noteID
+	s.revisions = append(s.revisions, r)
+
+	return r.ID
+}
+
+func (s *fakeStorage) GetNote(ctx context.Context, userID, noteID int64) (models.Note, error) {
+	for _, note := range s.notes {
+		if note.UserID == userID && note.ID == noteID {
+			return note, nil
+		}
+	}
+
+	return models.Note{}, storage.ErrNoteNotFound
+}
+
+func (s *fakeStorage) GetDailyNote(ctx context.Context, userID int64, date string) (models.Note, error) {
+	for _, note := range s.notes {
+		if note.UserID == userID && note.DailyDate == date {
+			return note, nil
+		}
+	}
+
+	return models.Note{}, storage.ErrNoteNotFound
+}
+
+func (s *fakeStorage) GetNoteFingerprints(ctx context.Context, userID int64) ([]models.Fingerprint, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) GetDocumentFrequencies(ctx context.Context, userID int64, terms []string) (map[string]int, int, error) {
+	return nil, 0, nil
+}
+
+func (s *fakeStorage) SaveNoteTerms(ctx context.Context, userID, noteID int64, weights map[string]float64) error {
+	return nil
+}
+
+func (s *fakeStorage) SaveActivity(ctx context.Context, activity models.NoteActivity) error {
+	return nil
+}
+
+func (s *fakeStorage) GetActivity(ctx context.Context, ownerID, noteID int64) ([]models.NoteActivity, error) {
+	return []models.NoteActivity{}, nil
+}
+
+func (s *fakeStorage) NoteOwnedBy(ctx context.Context, userID, noteID int64) (bool, error) {
+	for _, note := range s.notes {
+		if note.UserID == userID && note.ID == noteID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *fakeStorage) GetRules(ctx context.Context, userID int64) ([]models.Rule, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) EnqueueSpellcheckJob(ctx context.Context, userID, noteID int64, runAt time.Time) error {
+	return nil
+}
+
+func (s *fakeStorage) GetRevision(ctx context.Context, userID, noteID, revisionID int64) (models.NoteRevision, error) {
+	for _, revision := range s.revisions {
+		if revision.UserID == userID && revision.NoteID == noteID && revision.ID == revisionID {
+			return revision, nil
+		}
+	}
+
+	return models.NoteRevision{}, storage.ErrRevisionNotFound
+}
+
+func (s *fakeStorage) GetSpellingErrors(ctx context.Context, userID, noteID, revisionID int64) ([]models.SpellingAnnotation, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) SaveSpellingErrors(ctx context.Context, userID, noteID, revisionID int64, annotations []models.SpellingAnnotation) error {
+	return nil
+}
+
+func (s *fakeStorage) GetComments(ctx context.Context, ownerID, noteID int64) ([]models.Comment, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) ClaimSpellcheckJob(ctx context.Context, now, lockedUntil time.Time) (models.SpellcheckJob, error) {
+	if len(s.jobs) == 0 {
+		return models.SpellcheckJob{}, storage.ErrNoJobs
+	}
+
+	job := s.jobs[0]
+	s.jobs = s.jobs[1:]
+	job.Attempts++
+
+	return job, nil
+}
+
+func (s *fakeStorage) DeadLetterSpellcheckJob(ctx context.Context, job models.SpellcheckJob, jobErr string) error {
+	job.Status = models.JobDead
+	job.Error = jobErr
+	s.deadJobs = append(s.deadJobs, job)
+
+	return nil
+}
+
+func newTestService(s *fakeStorage) *NoteService {
+	var (
+		stats      config.Stats
+		daily      config.Daily
+		spellcheck config.SpellChecker
+	)
+	stats.Timezone = "UTC"
+	daily.Template = models.DailyDate
+	spellcheck.Async = true
+	spellcheck.JobAttempts = 5
+
+	managers := Managers{
+		Notes:          s,
+		Rules:          s,
+		Fields:         s,
+		Activity:       s,
+		Settings:       s,
+		SpellcheckJobs: s,
+		Revisions:      s,
+		Spelling:       s,
+		Comments:       s,
+	}
+
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), managers, nil, nil, config.Limits{}, stats, daily, spellcheck)
+}
+
+func TestRestoreRevisionKeepsReplacedText(t *testing.T) {
+	s := &fakeStorage{
+		notes:     []models.Note{{ID: 1, UserID: 1, Note: "current text"}},
+		revisions: []models.NoteRevision{{ID: 1, NoteID: 1, UserID: 1, Note: "earlier text", Reason: models.RevisionAutocorrect}},
+	}
+	ns := newTestService(s)
+
+	if _, err := ns.RestoreRevision(context.Background(), 1, 1, 1); err != nil {
+		t.Fatalf("RestoreRevision() error = %v", err)
+	}
+
+	if s.notes[0].Note != "earlier text" {
+		t.Errorf("RestoreRevision() note = %q, want earlier text", s.notes[0].Note)
+	}
+
+	if len(s.revisions) != 2 || s.revisions[1].Note != "current text" || s.revisions[1].Reason != models.RevisionRestore {
+		t.Errorf("RestoreRevision() revisions = %+v, want replaced text kept", s.revisions)
+	}
+}
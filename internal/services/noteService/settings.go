@@ -0,0 +1,84 @@
+package noteservice
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+type SettingsManager interface {
+	GetUserSettings(ctx context.Context, userID int64) (models.UserSettings, error)
+	SaveUserSettings(ctx context.Context, userID int64, settings models.UserSettings) error
+}
+
+func (ns *NoteService) GetSettings(ctx context.Context, userID int64) (models.UserSettings, error) {
+	const op = "services.NoteService.GetSettings"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get settings")
+
+	settings, err := ns.settingsManager.GetUserSettings(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user not found", sl.Err(err))
+
+			return models.UserSettings{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get settings", sl.Err(err))
+
+		return models.UserSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("settings got successfully")
+
+	return settings, nil
+}
+
+func (ns *NoteService) UpdateSettings(ctx context.Context, userID int64, settings models.UserSettings) error {
+	const op = "services.NoteService.UpdateSettings"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to update settings")
+
+	err := ns.settingsManager.SaveUserSettings(ctx, userID, settings)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to save settings", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("settings updated successfully")
+
+	return nil
+}
+
+// userLocation returns the timezone of the user falling back to the configured one
+func (ns *NoteService) userLocation(ctx context.Context, userID int64) (*time.Location, models.UserSettings, error) {
+	settings, err := ns.settingsManager.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, models.UserSettings{}, err
+	}
+
+	loc, err := time.LoadLocation(cmp.Or(settings.Timezone, ns.stats.Timezone))
+	if err != nil {
+		return nil, models.UserSettings{}, err
+	}
+
+	return loc, settings, nil
+}
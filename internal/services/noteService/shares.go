@@ -0,0 +1,167 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var (
+	ErrForbidden     = errors.New("action is not allowed")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrShareExists   = errors.New("note already shared with user")
+	ErrShareNotFound = errors.New("share not found")
+	ErrShareWithSelf = errors.New("note can't be shared with its owner")
+)
+
+type SharesManager interface {
+	NoteOwner(ctx context.Context, userID, noteID int64) (ownerID int64, err error)
+	SaveShare(ctx context.Context, noteID, userID int64) error
+	DeleteShare(ctx context.Context, noteID, userID int64) error
+	GetShares(ctx context.Context, noteID int64) ([]models.NoteShare, error)
+	UserByEmail(ctx context.Context, email string) (models.User, error)
+}
+
+func (ns *NoteService) ShareNote(ctx context.Context, userID, noteID int64, email string) error {
+	const op = "services.NoteService.ShareNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to share note")
+
+	if err := ns.checkOwner(ctx, log, userID, noteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := ns.sharesManager.UserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Warn("user not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to get user", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.ID == userID {
+		log.Warn("note shared with its owner")
+
+		return fmt.Errorf("%s: %w", op, ErrShareWithSelf)
+	}
+
+	err = ns.sharesManager.SaveShare(ctx, noteID, user.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrShareExists) {
+			log.Warn("note already shared", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrShareExists)
+		}
+
+		log.Error("failed to save share", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.recordActivity(ctx, log, models.NoteActivity{NoteID: noteID, OwnerID: userID, ActorID: userID, Action: models.ActionShared})
+
+	log.Info("note shared successfully")
+
+	return nil
+}
+
+func (ns *NoteService) UnshareNote(ctx context.Context, userID, noteID, recipientID int64) error {
+	const op = "services.NoteService.UnshareNote"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to unshare note")
+
+	if err := ns.checkOwner(ctx, log, userID, noteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := ns.sharesManager.DeleteShare(ctx, noteID, recipientID)
+	if err != nil {
+		if errors.Is(err, storage.ErrShareNotFound) {
+			log.Warn("share not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrShareNotFound)
+		}
+
+		log.Error("failed to delete share", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ns.recordActivity(ctx, log, models.NoteActivity{NoteID: noteID, OwnerID: userID, ActorID: userID, Action: models.ActionUnshared})
+
+	log.Info("note unshared successfully")
+
+	return nil
+}
+
+// GetShares lists users the note is shared with, it is visible to everyone with access to the note
+func (ns *NoteService) GetShares(ctx context.Context, userID, noteID int64) ([]models.NoteShare, error) {
+	const op = "services.NoteService.GetShares"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get shares")
+
+	if _, err := ns.noteOwner(ctx, log, userID, noteID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	shares, err := ns.sharesManager.GetShares(ctx, noteID)
+	if err != nil {
+		log.Error("failed to get shares", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("shares got successfully")
+
+	return shares, nil
+}
+
+// noteOwner returns the owner of the note if the user has access to it
+func (ns *NoteService) noteOwner(ctx context.Context, log *slog.Logger, userID, noteID int64) (int64, error) {
+	ownerID, err := ns.sharesManager.NoteOwner(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return 0, ErrNoteNotFound
+		}
+
+		log.Error("failed to get note owner", sl.Err(err))
+
+		return 0, err
+	}
+
+	return ownerID, nil
+}
+
+// checkOwner fails with ErrForbidden if the note is only shared with the user
+func (ns *NoteService) checkOwner(ctx context.Context, log *slog.Logger, userID, noteID int64) error {
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return err
+	}
+
+	if ownerID != userID {
+		log.Warn("user is not the note owner")
+
+		return ErrForbidden
+	}
+
+	return nil
+}
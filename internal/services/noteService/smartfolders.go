@@ -0,0 +1,193 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var (
+	ErrSmartFolderExists   = errors.New("smart folder already exists")
+	ErrSmartFolderNotFound = errors.New("smart folder not found")
+)
+
+type SmartFoldersManager interface {
+	SaveSmartFolder(ctx context.Context, userID int64, folder models.SmartFolder) (folderID int64, err error)
+	UpdateSmartFolder(ctx context.Context, userID int64, folder models.SmartFolder) error
+	DeleteSmartFolder(ctx context.Context, userID, folderID int64) error
+	GetSmartFolder(ctx context.Context, userID, folderID int64) (models.SmartFolder, error)
+	GetSmartFolders(ctx context.Context, userID int64) ([]models.SmartFolder, error)
+	GetSmartFolderNotes(ctx context.Context, userID int64, folder models.SmartFolder, page, limit int) ([]models.Note, error)
+}
+
+func (ns *NoteService) CreateSmartFolder(ctx context.Context, userID int64, req models.SmartFolderRequest) (int64, error) {
+	const op = "services.NoteService.CreateSmartFolder"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to create smart folder")
+
+	folder, err := newSmartFolder(req)
+	if err != nil {
+		log.Warn("invalid search query", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := ns.smartFoldersManager.SaveSmartFolder(ctx, userID, folder)
+	if err != nil {
+		if errors.Is(err, storage.ErrSmartFolderExists) {
+			log.Warn("smart folder already exists", sl.Err(err))
+
+			return 0, fmt.Errorf("%s: %w", op, ErrSmartFolderExists)
+		}
+
+		log.Error("failed to save smart folder", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("smart folder created successfully")
+
+	return id, nil
+}
+
+func (ns *NoteService) UpdateSmartFolder(ctx context.Context, userID, folderID int64, req models.SmartFolderRequest) error {
+	const op = "services.NoteService.UpdateSmartFolder"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to update smart folder")
+
+	folder, err := newSmartFolder(req)
+	if err != nil {
+		log.Warn("invalid search query", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	folder.ID = folderID
+
+	err = ns.smartFoldersManager.UpdateSmartFolder(ctx, userID, folder)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrSmartFolderNotFound):
+			log.Warn("smart folder not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSmartFolderNotFound)
+		case errors.Is(err, storage.ErrSmartFolderExists):
+			log.Warn("smart folder already exists", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSmartFolderExists)
+		}
+
+		log.Error("failed to update smart folder", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("smart folder updated successfully")
+
+	return nil
+}
+
+func (ns *NoteService) DeleteSmartFolder(ctx context.Context, userID, folderID int64) error {
+	const op = "services.NoteService.DeleteSmartFolder"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to delete smart folder")
+
+	err := ns.smartFoldersManager.DeleteSmartFolder(ctx, userID, folderID)
+	if err != nil {
+		if errors.Is(err, storage.ErrSmartFolderNotFound) {
+			log.Warn("smart folder not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSmartFolderNotFound)
+		}
+
+		log.Error("failed to delete smart folder", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("smart folder deleted successfully")
+
+	return nil
+}
+
+// GetSmartFolders returns smart folders of the user with the number of matching notes
+func (ns *NoteService) GetSmartFolders(ctx context.Context, userID int64) ([]models.SmartFolder, error) {
+	const op = "services.NoteService.GetSmartFolders"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get smart folders")
+
+	folders, err := ns.smartFoldersManager.GetSmartFolders(ctx, userID)
+	if err != nil {
+		log.Error("failed to get smart folders", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("smart folders got successfully")
+
+	return folders, nil
+}
+
+// GetSmartFolderNotes evaluates the saved definition of the folder
+func (ns *NoteService) GetSmartFolderNotes(ctx context.Context, userID, folderID int64, page, limit int) ([]models.Note, error) {
+	const op = "services.NoteService.GetSmartFolderNotes"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get smart folder notes")
+
+	folder, err := ns.smartFoldersManager.GetSmartFolder(ctx, userID, folderID)
+	if err != nil {
+		if errors.Is(err, storage.ErrSmartFolderNotFound) {
+			log.Warn("smart folder not found", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrSmartFolderNotFound)
+		}
+
+		log.Error("failed to get smart folder", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	notes, err := ns.smartFoldersManager.GetSmartFolderNotes(ctx, userID, folder, page, limit)
+	if err != nil {
+		log.Error("failed to get smart folder notes", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("smart folder notes got successfully")
+
+	return notes, nil
+}
+
+// newSmartFolder stores the definition in the current version, syntax errors of the query
+// are returned as *searchquery.Error
+func newSmartFolder(req models.SmartFolderRequest) (models.SmartFolder, error) {
+	definition := req.Definition
+	definition.Version = models.SmartFolderVersion
+
+	if definition.Query != "" {
+		if _, err := searchquery.Parse(definition.Query); err != nil {
+			return models.SmartFolder{}, err
+		}
+	}
+
+	return models.SmartFolder{
+		Name:       req.Name,
+		Definition: definition,
+	}, nil
+}
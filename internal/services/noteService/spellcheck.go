@@ -0,0 +1,141 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var ErrSpellcheckUnavailable = errors.New("spellchecker is unavailable")
+
+// RateLimitError is returned when the user checks spelling too often
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many spellcheck requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// CheckSpelling checks arbitrary text without saving it, requests of a user are rate limited
+func (ns *NoteService) CheckSpelling(ctx context.Context, userID int64, text string) ([]models.SpellError, error) {
+	const op = "services.NoteService.CheckSpelling"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to check spelling")
+
+	if allowed, retryAfter := ns.spellcheckLimiter.Allow(userID); !allowed {
+		log.Warn("spellcheck rate limit exceeded")
+
+		return nil, fmt.Errorf("%s: %w", op, &RateLimitError{RetryAfter: retryAfter})
+	}
+
+	if err := ns.checkNoteLength(text); err != nil {
+		log.Warn("text is too long", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	spellingErrors, err := ns.spellcheckText(ctx, log, userID, text)
+	if err != nil {
+		log.Warn("spellchecker is unavailable", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, ErrSpellcheckUnavailable)
+	}
+
+	log.Info("spelling checked successfully")
+
+	return spellingErrors, nil
+}
+
+// CheckNoteSpelling checks the stored text of the note and replaces its stored spelling errors
+func (ns *NoteService) CheckNoteSpelling(ctx context.Context, userID, noteID int64) ([]models.SpellError, error) {
+	const op = "services.NoteService.CheckNoteSpelling"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to check note spelling")
+
+	note, err := ns.notesManager.GetNote(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	spellingErrors, err := ns.spellcheckText(ctx, log, userID, note.Note)
+	if err != nil {
+		log.Warn("spellchecker is unavailable", sl.Err(err))
+
+		return nil, fmt.Errorf("%s: %w", op, ErrSpellcheckUnavailable)
+	}
+
+	ns.saveSpellingErrors(ctx, log, userID, noteID, spellingErrors)
+
+	log.Info("note spelling checked successfully")
+
+	return spellingErrors, nil
+}
+
+// checkSpelling returns spelling errors of the text and the spellcheck status.
+// Failures of the spellchecker are logged, the note is saved anyway.
+func (ns *NoteService) checkSpelling(ctx context.Context, log *slog.Logger, userID int64, text string) ([]models.SpellError, string) {
+	spellingErrors, err := ns.spellcheckText(ctx, log, userID, text)
+	if err != nil {
+		log.Warn("spellchecker is unavailable", sl.Err(err))
+
+		return nil, models.SpellcheckUnavailable
+	}
+
+	return spellingErrors, models.SpellcheckOK
+}
+
+// spellcheckText is limited by the spellcheck budget, so it leaves time to save the note.
+// The result is never nil.
+func (ns *NoteService) spellcheckText(ctx context.Context, log *slog.Logger, userID int64, text string) ([]models.SpellError, error) {
+	if ns.spellcheck.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ns.spellcheck.Budget)
+		defer cancel()
+	}
+
+	return ns.checkText(ctx, log, userID, text)
+}
+
+// checkText does not report words of the personal dictionary as errors. The result is never nil.
+func (ns *NoteService) checkText(ctx context.Context, log *slog.Logger, userID int64, text string) ([]models.SpellError, error) {
+	words := ns.personalWords(ctx, log, userID)
+
+	var spellingErrors []models.SpellError
+	var err error
+	if wordsChecker, ok := ns.spellChecker.(WordsSpellChecker); ok && len(words) > 0 {
+		spellingErrors, err = wordsChecker.CheckSpellingWithWords(ctx, text, words)
+	} else {
+		spellingErrors, err = ns.spellChecker.CheckSpelling(ctx, text)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// online spellcheckers do not know the dictionary
+	spellingErrors = filterPersonalWords(spellingErrors, words)
+
+	if spellingErrors == nil {
+		spellingErrors = []models.SpellError{}
+	}
+
+	return spellingErrors, nil
+}
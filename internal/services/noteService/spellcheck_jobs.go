@@ -0,0 +1,245 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+const (
+	// maxQueueJobs limits jobs listed in the admin view of the queue
+	maxQueueJobs = 100
+
+	// errJobAbandoned is the error of a job whose worker stopped during the last attempt
+	errJobAbandoned = "worker stopped during the last attempt"
+)
+
+var ErrSpellcheckJobNotFound = errors.New("spellcheck job not found")
+
+type SpellcheckJobsManager interface {
+	EnqueueSpellcheckJob(ctx context.Context, userID, noteID int64, runAt time.Time) error
+	ClaimSpellcheckJob(ctx context.Context, now, lockedUntil time.Time) (models.SpellcheckJob, error)
+	CompleteSpellcheckJob(ctx context.Context, job models.SpellcheckJob) error
+	RescheduleSpellcheckJob(ctx context.Context, job models.SpellcheckJob, jobErr string, runAt time.Time) error
+	DeadLetterSpellcheckJob(ctx context.Context, job models.SpellcheckJob, jobErr string) error
+	RequeueSpellcheckJob(ctx context.Context, jobID int64, runAt time.Time) error
+	GetSpellcheckJobs(ctx context.Context, status string, limit int) ([]models.SpellcheckJob, error)
+	CountSpellcheckJobs(ctx context.Context) (map[string]int64, error)
+	GetNoteSpellcheckJobStatus(ctx context.Context, noteID int64) (string, error)
+}
+
+// GetNoteSpelling returns open spelling errors of the note with the status of its background check:
+// pending until the job is finished and unavailable when the job is dead-lettered
+func (ns *NoteService) GetNoteSpelling(ctx context.Context, userID, noteID int64) (models.NoteSpelling, error) {
+	const op = "services.NoteService.GetNoteSpelling"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get note spelling")
+
+	ownerID, err := ns.noteOwner(ctx, log, userID, noteID)
+	if err != nil {
+		return models.NoteSpelling{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	jobStatus, err := ns.spellcheckJobsManager.GetNoteSpellcheckJobStatus(ctx, noteID)
+	if err != nil {
+		log.Error("failed to get spellcheck job status", sl.Err(err))
+
+		return models.NoteSpelling{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	spelling := models.NoteSpelling{SpellcheckStatus: models.SpellcheckOK}
+	switch jobStatus {
+	case models.JobPending, models.JobRunning:
+		spelling.SpellcheckStatus = models.SpellcheckPending
+	case models.JobDead:
+		spelling.SpellcheckStatus = models.SpellcheckUnavailable
+	}
+
+	annotations, err := ns.spellingManager.GetSpellingErrors(ctx, ownerID, noteID, 0)
+	if err != nil {
+		log.Error("failed to get spelling errors", sl.Err(err))
+
+		return models.NoteSpelling{}, fmt.Errorf("%s: %w", op, err)
+	}
+	spelling.SpellingErrors = openAnnotations(annotations)
+
+	log.Info("note spelling got successfully")
+
+	return spelling, nil
+}
+
+// ProcessSpellcheckJob checks the note of the next due job and stores its errors.
+// It returns false when no job is due. A failed job is retried with exponential backoff,
+// after the last attempt it is dead-lettered. A job claimed again after the last attempt
+// is dead-lettered without running.
+func (ns *NoteService) ProcessSpellcheckJob(ctx context.Context) (bool, error) {
+	const op = "services.NoteService.ProcessSpellcheckJob"
+
+	log := ns.log.With(slog.String("op", op))
+
+	now := time.Now()
+	job, err := ns.spellcheckJobsManager.ClaimSpellcheckJob(ctx, now, now.Add(ns.spellcheck.JobTimeout))
+	if err != nil {
+		if errors.Is(err, storage.ErrNoJobs) {
+			return false, nil
+		}
+
+		log.Error("failed to claim spellcheck job", sl.Err(err))
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("jobID", job.ID), slog.Int("attempt", job.Attempts))
+
+	// the worker of the last attempt crashed or panicked without failing the job, its lock expired
+	if job.Attempts > max(ns.spellcheck.JobAttempts, 1) {
+		log.Error("spellcheck job exceeded attempts, dead-lettering it")
+
+		if err := ns.spellcheckJobsManager.DeadLetterSpellcheckJob(ctx, job, errJobAbandoned); err != nil {
+			log.Error("failed to dead-letter spellcheck job", sl.Err(err))
+
+			return true, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return true, nil
+	}
+
+	log.Info("attempting to run spellcheck job")
+
+	if err := ns.runSpellcheckJob(ctx, log, job); err != nil {
+		ns.failSpellcheckJob(ctx, log, job, err)
+
+		return true, nil
+	}
+
+	if err := ns.spellcheckJobsManager.CompleteSpellcheckJob(ctx, job); err != nil {
+		log.Error("failed to complete spellcheck job", sl.Err(err))
+
+		return true, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("spellcheck job finished successfully")
+
+	return true, nil
+}
+
+// runSpellcheckJob checks the current text of the note, a deleted note needs no check
+func (ns *NoteService) runSpellcheckJob(ctx context.Context, log *slog.Logger, job models.SpellcheckJob) error {
+	if ns.spellcheck.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ns.spellcheck.JobTimeout)
+		defer cancel()
+	}
+
+	note, err := ns.notesManager.GetNote(ctx, job.UserID, job.NoteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return nil
+		}
+
+		return err
+	}
+
+	spellingErrors, err := ns.checkText(ctx, log, job.UserID, note.Note)
+	if err != nil {
+		return err
+	}
+
+	ns.saveSpellingErrors(ctx, log, job.UserID, job.NoteID, spellingErrors)
+
+	return nil
+}
+
+func (ns *NoteService) failSpellcheckJob(ctx context.Context, log *slog.Logger, job models.SpellcheckJob, jobErr error) {
+	if job.Attempts >= ns.spellcheck.JobAttempts {
+		log.Error("spellcheck job failed, dead-lettering it", sl.Err(jobErr))
+
+		if err := ns.spellcheckJobsManager.DeadLetterSpellcheckJob(ctx, job, jobErr.Error()); err != nil {
+			log.Error("failed to dead-letter spellcheck job", sl.Err(err))
+		}
+
+		return
+	}
+
+	runAt := time.Now().Add(ns.spellcheck.JobBackoff << (job.Attempts - 1))
+
+	log.Warn("spellcheck job failed, retrying it", sl.Err(jobErr), slog.Time("runAt", runAt))
+
+	if err := ns.spellcheckJobsManager.RescheduleSpellcheckJob(ctx, job, jobErr.Error(), runAt); err != nil {
+		log.Error("failed to reschedule spellcheck job", sl.Err(err))
+	}
+}
+
+// enqueueSpellcheck defers the check of the saved note to a worker, failures are logged
+func (ns *NoteService) enqueueSpellcheck(ctx context.Context, log *slog.Logger, userID, noteID int64) string {
+	if err := ns.spellcheckJobsManager.EnqueueSpellcheckJob(ctx, userID, noteID, time.Now()); err != nil {
+		log.Error("failed to enqueue spellcheck job", sl.Err(err))
+
+		return models.SpellcheckUnavailable
+	}
+
+	return models.SpellcheckPending
+}
+
+// GetSpellcheckQueue returns the number of jobs by status and the latest jobs with the status, all for an empty one
+func (ns *NoteService) GetSpellcheckQueue(ctx context.Context, status string) (models.SpellcheckQueue, error) {
+	const op = "services.NoteService.GetSpellcheckQueue"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get spellcheck queue")
+
+	counts, err := ns.spellcheckJobsManager.CountSpellcheckJobs(ctx)
+	if err != nil {
+		log.Error("failed to count spellcheck jobs", sl.Err(err))
+
+		return models.SpellcheckQueue{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	jobs, err := ns.spellcheckJobsManager.GetSpellcheckJobs(ctx, status, maxQueueJobs)
+	if err != nil {
+		log.Error("failed to get spellcheck jobs", sl.Err(err))
+
+		return models.SpellcheckQueue{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("spellcheck queue got successfully")
+
+	return models.SpellcheckQueue{Counts: counts, Jobs: jobs}, nil
+}
+
+// RetrySpellcheckJob returns the dead-lettered job to the queue
+func (ns *NoteService) RetrySpellcheckJob(ctx context.Context, jobID int64) error {
+	const op = "services.NoteService.RetrySpellcheckJob"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to retry spellcheck job")
+
+	err := ns.spellcheckJobsManager.RequeueSpellcheckJob(ctx, jobID, time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrJobNotFound) {
+			log.Warn("dead spellcheck job not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSpellcheckJobNotFound)
+		}
+
+		log.Error("failed to requeue spellcheck job", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("spellcheck job retried successfully")
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package noteservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func TestProcessSpellcheckJobDeadLettersAbandonedJob(t *testing.T) {
+	// the job was claimed 5 times, every worker crashed before failing it
+	s := &fakeStorage{jobs: []models.SpellcheckJob{{ID: 1, NoteID: 1, UserID: 1, Status: models.JobRunning, Attempts: 5}}}
+	ns := newTestService(s)
+
+	processed, err := ns.ProcessSpellcheckJob(context.Background())
+	if err != nil || !processed {
+		t.Fatalf("ProcessSpellcheckJob() = %v, %v, want processed job", processed, err)
+	}
+
+	if len(s.deadJobs) != 1 || s.deadJobs[0].ID != 1 || s.deadJobs[0].Error != errJobAbandoned {
+		t.Errorf("ProcessSpellcheckJob() dead jobs = %+v, want abandoned job 1", s.deadJobs)
+	}
+}
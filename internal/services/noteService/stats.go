@@ -0,0 +1,56 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// GetStats returns writing statistics of the user, the heatmap covers the last year.
+// Empty timezone means the one from user settings or the configured default.
+func (ns *NoteService) GetStats(ctx context.Context, userID int64, timezone string) (models.Stats, error) {
+	const op = "services.NoteService.GetStats"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get stats")
+
+	var loc *time.Location
+	var err error
+	if timezone == "" {
+		loc, _, err = ns.userLocation(ctx, userID)
+		if err != nil {
+			log.Error("failed to get user timezone", sl.Err(err))
+
+			return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+		}
+	} else {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			log.Warn("invalid timezone", sl.Err(err))
+
+			return models.Stats{}, fmt.Errorf("%s: %w", op, ErrInvalidTimezone)
+		}
+	}
+
+	now := time.Now().In(loc)
+	since := time.Date(now.Year()-1, now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+
+	stats, err := ns.notesManager.GetStats(ctx, userID, loc.String(), since)
+	if err != nil {
+		log.Error("failed to get stats", sl.Err(err))
+
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("stats got successfully")
+
+	return stats, nil
+}
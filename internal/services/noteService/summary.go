@@ -0,0 +1,88 @@
+package noteservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/lib/textrank"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+const (
+	// headlineLength limits the cached one-line summary shown in listings
+	headlineLength = 200
+
+	maxSummarySentences = 10
+	maxKeywords         = 50
+)
+
+// GetSummary returns the most central sentences of the note and its keywords
+// weighted by TF-IDF against the indexed notes of the user
+func (ns *NoteService) GetSummary(ctx context.Context, userID, noteID int64, sentences, keywords int) (models.NoteSummary, error) {
+	const op = "services.NoteService.GetSummary"
+
+	log := ns.log.With(slog.String("op", op))
+
+	log.Info("attempting to get summary")
+
+	sentences = min(max(sentences, 1), maxSummarySentences)
+	keywords = min(max(keywords, 1), maxKeywords)
+
+	note, err := ns.notesManager.GetNote(ctx, userID, noteID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoteNotFound) {
+			log.Warn("note not found", sl.Err(err))
+
+			return models.NoteSummary{}, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		log.Error("failed to get note", sl.Err(err))
+
+		return models.NoteSummary{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	counts := textrank.TermCounts(note.Note)
+
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+
+	docFreq, docs, err := ns.notesManager.GetDocumentFrequencies(ctx, userID, terms)
+	if err != nil {
+		log.Error("failed to get document frequencies", sl.Err(err))
+
+		return models.NoteSummary{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	summary := models.NoteSummary{
+		NoteID:    noteID,
+		Sentences: textrank.Summarize(note.Note, sentences),
+		Keywords:  []models.Keyword{},
+	}
+
+	for _, k := range textrank.Keywords(counts, docFreq, docs, keywords) {
+		summary.Keywords = append(summary.Keywords, models.Keyword{Term: k.Term, Score: k.Score})
+	}
+
+	log.Info("summary got successfully")
+
+	return summary, nil
+}
+
+// withSummaries computes headlines of notes saved before summaries were cached
+// or clears them if they were not requested
+func withSummaries(notes []models.Note, include bool) {
+	for i := range notes {
+		switch {
+		case !include:
+			notes[i].Summary = ""
+		case notes[i].Summary == "":
+			notes[i].Summary = textrank.Headline(notes[i].Note, headlineLength)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package notificationservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// Channel delivers notifications to users who enabled it in their preferences
+type Channel interface {
+	Name() string
+	Enabled(prefs models.NotificationPreferences) bool
+	Send(ctx context.Context, recipient models.User, prefs models.NotificationPreferences, n models.Notification) error
+}
+
+// InAppChannel stores notifications for the inbox
+type InAppChannel struct {
+	saver NotificationSaver
+}
+
+type NotificationSaver interface {
+	SaveNotification(ctx context.Context, n models.Notification) (notificationID int64, err error)
+}
+
+func NewInAppChannel(saver NotificationSaver) *InAppChannel {
+	return &InAppChannel{saver: saver}
+}
+
+func (c *InAppChannel) Name() string { return "in_app" }
+
+func (c *InAppChannel) Enabled(prefs models.NotificationPreferences) bool {
+	return prefs.InApp
+}
+
+func (c *InAppChannel) Send(ctx context.Context, _ models.User, _ models.NotificationPreferences, n models.Notification) error {
+	_, err := c.saver.SaveNotification(ctx, n)
+	return err
+}
+
+// WebhookChannel posts notifications as JSON to the URL from the user preferences.
+// It connects only to public addresses and doesn't follow redirects, a redirect is a failed delivery.
+type WebhookChannel struct {
+	client *http.Client
+}
+
+func NewWebhookChannel(timeout time.Duration) *WebhookChannel {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to the webhook instead of the checked dialer
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &WebhookChannel{client: &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+func (c *WebhookChannel) Name() string { return "webhook" }
+
+func (c *WebhookChannel) Enabled(prefs models.NotificationPreferences) bool {
+	return prefs.WebhookURL != ""
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, _ models.User, prefs models.NotificationPreferences, n models.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, prefs.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// EmailChannel sends notifications through an SMTP server
+type EmailChannel struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewEmailChannel uses plain auth if username is set, local stand-ins usually accept mail without it
+func NewEmailChannel(host, port, from, username, password string) *EmailChannel {
+	c := &EmailChannel{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+	}
+
+	if username != "" {
+		c.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return c
+}
+
+func (c *EmailChannel) Name() string { return "email" }
+
+func (c *EmailChannel) Enabled(prefs models.NotificationPreferences) bool {
+	return prefs.Email
+}
+
+// Send ignores ctx, net/smtp doesn't support cancellation
+func (c *EmailChannel) Send(_ context.Context, recipient models.User, _ models.NotificationPreferences, n models.Notification) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", n.Message)
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(n.Message + "\r\n")
+
+	return smtp.SendMail(c.addr, c.auth, c.from, []string{recipient.Email}, []byte(msg.String()))
+}
@@ -0,0 +1,257 @@
+package notificationservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/logger/sl"
+	"github.com/blankspace9/notes-app/internal/storage"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationService struct {
+	log                  *slog.Logger
+	notificationsManager NotificationsManager
+	userProvider         UserProvider
+	channels             []Channel
+
+	// deliveries limits concurrent deliveries, wg waits for them on stop
+	deliveries chan struct{}
+	wg         sync.WaitGroup
+}
+
+type NotificationsManager interface {
+	GetNotifications(ctx context.Context, userID int64, filter models.NotificationFilter) ([]models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (int64, error)
+	MarkNotificationRead(ctx context.Context, userID, notificationID int64) error
+	MarkAllNotificationsRead(ctx context.Context, userID int64) (int64, error)
+	GetNotificationPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error)
+	SaveNotificationPreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) error
+}
+
+type UserProvider interface {
+	UserById(ctx context.Context, id int64) (models.User, error)
+}
+
+// New returns a notification service delivering through the given channels,
+// at most maxDeliveries notifications are delivered at once
+func New(log *slog.Logger, notificationsManager NotificationsManager, userProvider UserProvider, maxDeliveries int, channels ...Channel) *NotificationService {
+	return &NotificationService{
+		log:                  log,
+		notificationsManager: notificationsManager,
+		userProvider:         userProvider,
+		channels:             channels,
+		deliveries:           make(chan struct{}, max(maxDeliveries, 1)),
+	}
+}
+
+// Notify delivers the notification in background through the channels enabled by the recipient.
+// It waits for a free delivery slot while the context is alive, otherwise the notification is dropped.
+// Delivery must not fail the action that caused it, so errors are only logged.
+func (s *NotificationService) Notify(ctx context.Context, n models.Notification) {
+	const op = "services.NotificationService.Notify"
+
+	select {
+	case s.deliveries <- struct{}{}:
+	case <-ctx.Done():
+		s.log.With(slog.String("op", op), slog.String("kind", n.Kind)).
+			Warn("no free delivery slot, notification dropped", sl.Err(ctx.Err()))
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.deliveries }()
+
+		s.deliver(context.WithoutCancel(ctx), n)
+	}()
+}
+
+// Stop waits for the started deliveries, it must be called once nothing calls Notify anymore
+func (s *NotificationService) Stop() {
+	const op = "services.NotificationService.Stop"
+
+	s.log.With(slog.String("op", op)).Info("waiting for notification deliveries")
+
+	s.wg.Wait()
+}
+
+func (s *NotificationService) deliver(ctx context.Context, n models.Notification) {
+	const op = "services.NotificationService.deliver"
+
+	log := s.log.With(slog.String("op", op), slog.String("kind", n.Kind))
+
+	prefs, err := s.notificationsManager.GetNotificationPreferences(ctx, n.UserID)
+	if err != nil {
+		log.Error("failed to get preferences", sl.Err(err))
+		return
+	}
+
+	if n.Kind == models.NotificationMention && !prefs.Mentions {
+		return
+	}
+
+	recipient, err := s.userProvider.UserById(ctx, n.UserID)
+	if err != nil {
+		log.Error("failed to get recipient", sl.Err(err))
+		return
+	}
+
+	n.Message, err = s.message(ctx, n)
+	if err != nil {
+		log.Error("failed to build message", sl.Err(err))
+		return
+	}
+	n.CreatedAt = time.Now()
+
+	for _, channel := range s.channels {
+		if !channel.Enabled(prefs) {
+			continue
+		}
+
+		if err := channel.Send(ctx, recipient, prefs, n); err != nil {
+			log.Error("failed to send notification", slog.String("channel", channel.Name()), sl.Err(err))
+		}
+	}
+}
+
+// message describes the notification without note contents, they are encrypted at rest
+func (s *NotificationService) message(ctx context.Context, n models.Notification) (string, error) {
+	actor, err := s.userProvider.UserById(ctx, n.ActorID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case n.Kind == models.NotificationMention && n.CommentID != 0:
+		return fmt.Sprintf("%s mentioned you in a comment on note %d", actor.Email, n.NoteID), nil
+	case n.Kind == models.NotificationMention:
+		return fmt.Sprintf("%s mentioned you in note %d", actor.Email, n.NoteID), nil
+	default:
+		return fmt.Sprintf("%s: %s", n.Kind, actor.Email), nil
+	}
+}
+
+// GetNotifications returns the notifications page and the number of unread notifications
+func (s *NotificationService) GetNotifications(ctx context.Context, userID int64, filter models.NotificationFilter) ([]models.Notification, int64, error) {
+	const op = "services.NotificationService.GetNotifications"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("attempting to get notifications")
+
+	notifications, err := s.notificationsManager.GetNotifications(ctx, userID, filter)
+	if err != nil {
+		log.Error("failed to get notifications", sl.Err(err))
+
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unread, err := s.notificationsManager.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		log.Error("failed to count unread notifications", sl.Err(err))
+
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("notifications got successfully")
+
+	return notifications, unread, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	const op = "services.NotificationService.MarkRead"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("attempting to mark notification as read")
+
+	err := s.notificationsManager.MarkNotificationRead(ctx, userID, notificationID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotificationNotFound) {
+			log.Warn("notification not found", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, ErrNotificationNotFound)
+		}
+
+		log.Error("failed to mark notification as read", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("notification marked as read successfully")
+
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	const op = "services.NotificationService.MarkAllRead"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("attempting to mark all notifications as read")
+
+	marked, err := s.notificationsManager.MarkAllNotificationsRead(ctx, userID)
+	if err != nil {
+		log.Error("failed to mark notifications as read", sl.Err(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("notifications marked as read successfully", slog.Int64("marked", marked))
+
+	return marked, nil
+}
+
+func (s *NotificationService) GetPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error) {
+	const op = "services.NotificationService.GetPreferences"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("attempting to get preferences")
+
+	prefs, err := s.notificationsManager.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		log.Error("failed to get preferences", sl.Err(err))
+
+		return models.NotificationPreferences{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("preferences got successfully")
+
+	return prefs, nil
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) error {
+	const op = "services.NotificationService.UpdatePreferences"
+
+	log := s.log.With(slog.String("op", op))
+
+	log.Info("attempting to update preferences")
+
+	if prefs.WebhookURL != "" {
+		if err := CheckWebhookURL(ctx, prefs.WebhookURL); err != nil {
+			log.Warn("forbidden webhook", sl.Err(err))
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	err := s.notificationsManager.SaveNotificationPreferences(ctx, userID, prefs)
+	if err != nil {
+		log.Error("failed to save preferences", sl.Err(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("preferences updated successfully")
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package notificationservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+type fakeStorage struct {
+	NotificationsManager
+}
+
+func (s *fakeStorage) GetNotificationPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error) {
+	return models.NotificationPreferences{Mentions: true, InApp: true}, nil
+}
+
+func (s *fakeStorage) UserById(ctx context.Context, id int64) (models.User, error) {
+	return models.User{ID: id}, nil
+}
+
+// blockingChannel holds every delivery until release is closed
+type blockingChannel struct {
+	release chan struct{}
+
+	mu      sync.Mutex
+	running int
+	peak    int
+	sent    atomic.Int64
+}
+
+func (c *blockingChannel) Name() string { return "blocking" }
+
+func (c *blockingChannel) Enabled(models.NotificationPreferences) bool { return true }
+
+func (c *blockingChannel) Send(context.Context, models.User, models.NotificationPreferences, models.Notification) error {
+	c.mu.Lock()
+	c.running++
+	c.peak = max(c.peak, c.running)
+	c.mu.Unlock()
+
+	<-c.release
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	c.sent.Add(1)
+
+	return nil
+}
+
+func TestNotifyBoundsDeliveries(t *testing.T) {
+	const maxDeliveries = 2
+
+	channel := &blockingChannel{release: make(chan struct{})}
+	s := &fakeStorage{}
+	ns := New(slog.New(slog.NewTextHandler(io.Discard, nil)), s, s, maxDeliveries, channel)
+
+	n := models.Notification{UserID: 2, ActorID: 1, NoteID: 1, Kind: models.NotificationMention}
+	for range maxDeliveries {
+		ns.Notify(context.Background(), n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// every slot is taken, so the notification waits until the context is done and is dropped
+	ns.Notify(ctx, n)
+
+	close(channel.release)
+	ns.Stop()
+
+	if got := channel.sent.Load(); got != maxDeliveries {
+		t.Errorf("sent %d notifications before Stop() returned, want %d", got, maxDeliveries)
+	}
+	if channel.peak > maxDeliveries {
+		t.Errorf("%d concurrent deliveries, want at most %d", channel.peak, maxDeliveries)
+	}
+}
@@ -0,0 +1,66 @@
+package notificationservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrWebhookForbidden is returned for webhooks to the internal network of the server
+var ErrWebhookForbidden = errors.New("webhook must point to a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range, it is not public but not private either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddress reports whether webhooks may connect to the address. Loopback, private, link-local,
+// multicast and unspecified addresses, including IPv4 ones mapped to IPv6, are internal.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// dialControl checks the resolved address right before connecting, so a DNS record changed
+// after CheckWebhookURL can't point the webhook to the internal network
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !publicAddress(addr) {
+		return fmt.Errorf("%w: %s", ErrWebhookForbidden, addr)
+	}
+
+	return nil
+}
+
+// CheckWebhookURL resolves the host of the webhook and refuses it if any of its addresses is internal
+func CheckWebhookURL(ctx context.Context, webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhookForbidden, err)
+	}
+
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrWebhookForbidden, u.Hostname(), addr)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package notificationservice
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:4700:4700::1111", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "10.1.2.3", want: false},
+		{addr: "172.16.0.1", want: false},
+		{addr: "192.168.1.1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "fd00::1", want: false},
+		{addr: "100.64.0.1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "224.0.0.1", want: false},
+		{addr: "::ffff:127.0.0.1", want: false},
+		{addr: "::ffff:10.0.0.1", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := publicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	if err := dialControl("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("dialControl(public) error = %v", err)
+	}
+
+	if err := dialControl("tcp", "[::1]:8080", nil); !errors.Is(err, ErrWebhookForbidden) {
+		t.Errorf("dialControl(loopback) error = %v, want %v", err, ErrWebhookForbidden)
+	}
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func (s *Storage) SaveActivity(ctx context.Context, activity models.NoteActivity) error {
+	const op = "storage.postgres.SaveActivity"
+
+	stmt, err := s.db.Prepare(`INSERT INTO note_activity(note_id, owner_id, actor_id, action, client_ip, user_agent, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7)`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	actorID := sql.NullInt64{Int64: activity.ActorID, Valid: activity.ActorID != 0}
+
+	_, err = stmt.ExecContext(ctx, activity.NoteID, activity.OwnerID, actorID, activity.Action,
+		activity.ClientIP, activity.UserAgent, activity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetActivity returns the trail of the note, including notes that were deleted
+func (s *Storage) GetActivity(ctx context.Context, ownerID, noteID int64) ([]models.NoteActivity, error) {
+	const op = "storage.postgres.GetActivity"
+
+	stmt, err := s.db.Prepare(`SELECT id, note_id, owner_id, actor_id, action, client_ip, user_agent, created_at
+		FROM note_activity WHERE note_id=$1 AND owner_id=$2 ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, noteID, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	activity := []models.NoteActivity{}
+	for rows.Next() {
+		var a models.NoteActivity
+		var actorID sql.NullInt64
+
+		err = rows.Scan(&a.ID, &a.NoteID, &a.OwnerID, &actorID, &a.Action, &a.ClientIP, &a.UserAgent, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		a.ActorID = actorID.Int64
+		activity = append(activity, a)
+	}
+
+	return activity, rows.Err()
+}
+
+// NoteOwnedBy reports whether the user owns the note, including notes that were deleted
+func (s *Storage) NoteOwnedBy(ctx context.Context, userID, noteID int64) (bool, error) {
+	const op = "storage.postgres.NoteOwnedBy"
+
+	stmt, err := s.db.Prepare("SELECT 1 FROM notes WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var owned int
+	err = stmt.QueryRowContext(ctx, noteID, userID).Scan(&owned)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// commentColumns are scanned by scanComments
+const commentColumns = `id, note_id, parent_id, author_id, body, key_id, anchor_start, anchor_end, anchor_text, anchor_detached,
+	resolved_by, resolved_at, created_at, updated_at`
+
+// SaveComment stores the comment sealed with the data key of the note owner
+func (s *Storage) SaveComment(ctx context.Context, ownerID int64, comment models.Comment) (int64, error) {
+	const op = "storage.postgres.SaveComment"
+
+	body, anchorText, keyID, err := s.sealComment(ctx, ownerID, comment)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	start, end, detached := anchorColumns(comment.Anchor)
+
+	stmt, err := s.db.Prepare(`INSERT INTO note_comments(note_id, parent_id, author_id, body, key_id,
+		anchor_start, anchor_end, anchor_text, anchor_detached, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	parentID := sql.NullInt64{Int64: comment.ParentID, Valid: comment.ParentID != 0}
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, comment.NoteID, parentID, comment.AuthorID, body, keyID,
+		start, end, anchorText, detached, comment.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// UpdateComment replaces the body and the anchor of the comment
+func (s *Storage) UpdateComment(ctx context.Context, ownerID int64, comment models.Comment) error {
+	const op = "storage.postgres.UpdateComment"
+
+	body, anchorText, keyID, err := s.sealComment(ctx, ownerID, comment)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	start, end, detached := anchorColumns(comment.Anchor)
+
+	stmt, err := s.db.Prepare(`UPDATE note_comments SET body=$1, key_id=$2, anchor_start=$3, anchor_end=$4, anchor_text=$5,
+		anchor_detached=$6, updated_at=$7 WHERE id=$8 AND note_id=$9`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, body, keyID, start, end, anchorText, detached, comment.UpdatedAt, comment.ID, comment.NoteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrCommentNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetCommentResolved marks the comment resolved by the user or clears the mark
+func (s *Storage) SetCommentResolved(ctx context.Context, noteID, commentID, userID int64, resolved bool) error {
+	const op = "storage.postgres.SetCommentResolved"
+
+	resolvedBy := sql.NullInt64{Int64: userID, Valid: resolved}
+	resolvedAt := sql.NullTime{Time: time.Now(), Valid: resolved}
+
+	stmt, err := s.db.Prepare("UPDATE note_comments SET resolved_by=$1, resolved_at=$2 WHERE id=$3 AND note_id=$4")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, resolvedBy, resolvedAt, commentID, noteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrCommentNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteComment removes the comment together with its replies
+func (s *Storage) DeleteComment(ctx context.Context, noteID, commentID int64) error {
+	const op = "storage.postgres.DeleteComment"
+
+	stmt, err := s.db.Prepare("DELETE FROM note_comments WHERE id=$1 AND note_id=$2")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, commentID, noteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrCommentNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetComment(ctx context.Context, ownerID, noteID, commentID int64) (models.Comment, error) {
+	const op = "storage.postgres.GetComment"
+
+	stmt, err := s.db.Prepare("SELECT " + commentColumns + " FROM note_comments WHERE id=$1 AND note_id=$2")
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, commentID, noteID)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	comments, err := s.scanComments(ctx, rows, ownerID)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(comments) == 0 {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, ErrCommentNotFound)
+	}
+
+	return comments[0], nil
+}
+
+// GetComments returns all comments of the note ordered by creation, threads are built by the caller
+func (s *Storage) GetComments(ctx context.Context, ownerID, noteID int64) ([]models.Comment, error) {
+	const op = "storage.postgres.GetComments"
+
+	stmt, err := s.db.Prepare("SELECT " + commentColumns + " FROM note_comments WHERE note_id=$1 ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	comments, err := s.scanComments(ctx, rows, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, nil
+}
+
+func (s *Storage) scanComments(ctx context.Context, rows *sql.Rows, ownerID int64) ([]models.Comment, error) {
+	comments := []models.Comment{}
+	for rows.Next() {
+		var c models.Comment
+		var parentID, authorID, keyID, resolvedBy, start, end sql.NullInt64
+		var anchorText sql.NullString
+		var detached bool
+		var resolvedAt, updatedAt sql.NullTime
+
+		err := rows.Scan(&c.ID, &c.NoteID, &parentID, &authorID, &c.Body, &keyID, &start, &end, &anchorText, &detached,
+			&resolvedBy, &resolvedAt, &c.CreatedAt, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Body, err = s.openText(ctx, s.db, ownerID, c.Body, keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		if start.Valid {
+			text, err := s.openText(ctx, s.db, ownerID, anchorText.String, keyID)
+			if err != nil {
+				return nil, err
+			}
+
+			c.Anchor = &models.Anchor{
+				Start:    int(start.Int64),
+				End:      int(end.Int64),
+				Text:     text,
+				Detached: detached,
+			}
+		}
+
+		c.ParentID = parentID.Int64
+		c.AuthorID = authorID.Int64
+		c.ResolvedBy = resolvedBy.Int64
+		c.Resolved = resolvedAt.Valid
+		if resolvedAt.Valid {
+			c.ResolvedAt = &resolvedAt.Time
+		}
+		if updatedAt.Valid {
+			c.UpdatedAt = &updatedAt.Time
+		}
+
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// sealComment encrypts the body and the anchor text with the same data key of the note owner
+func (s *Storage) sealComment(ctx context.Context, ownerID int64, comment models.Comment) (string, sql.NullString, sql.NullInt64, error) {
+	if comment.Anchor == nil {
+		body, keyID, err := s.sealText(ctx, s.db, ownerID, comment.Body)
+		return body, sql.NullString{}, keyID, err
+	}
+
+	sealed, keyID, err := s.sealTexts(ctx, s.db, ownerID, comment.Body, comment.Anchor.Text)
+	if err != nil {
+		return "", sql.NullString{}, sql.NullInt64{}, err
+	}
+
+	return sealed[0], sql.NullString{String: sealed[1], Valid: true}, keyID, nil
+}
+
+func anchorColumns(anchor *models.Anchor) (start, end sql.NullInt64, detached bool) {
+	if anchor == nil {
+		return sql.NullInt64{}, sql.NullInt64{}, false
+	}
+
+	return sql.NullInt64{Int64: int64(anchor.Start), Valid: true}, sql.NullInt64{Int64: int64(anchor.End), Valid: true}, anchor.Detached
+}
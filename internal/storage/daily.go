@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// GetDailyNote returns the journal note of the day in DateLayout
+func (s *Storage) GetDailyNote(ctx context.Context, userID int64, date string) (models.Note, error) {
+	const op = "storage.postgres.GetDailyNote"
+
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND daily_date=$2 AND deleted_at IS NULL")
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, date)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(notes) == 0 {
+		return models.Note{}, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+	}
+
+	return notes[0], nil
+}
+
+// GetDailyEntries returns journal notes of the days in [from, to)
+func (s *Storage) GetDailyEntries(ctx context.Context, userID int64, from, to string) ([]models.DailyEntry, error) {
+	const op = "storage.postgres.GetDailyEntries"
+
+	stmt, err := s.db.Prepare(`SELECT to_char(daily_date, 'YYYY-MM-DD'), id FROM notes
+		WHERE user_id=$1 AND deleted_at IS NULL AND daily_date >= $2 AND daily_date < $3 ORDER BY daily_date`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := []models.DailyEntry{}
+	for rows.Next() {
+		var entry models.DailyEntry
+		if err := rows.Scan(&entry.Date, &entry.NoteID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
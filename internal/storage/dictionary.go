@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetDictionary returns words of the personal dictionary in alphabetical order
+func (s *Storage) GetDictionary(ctx context.Context, userID int64) ([]string, error) {
+	const op = "storage.postgres.GetDictionary"
+
+	stmt, err := s.db.Prepare("SELECT word FROM dictionary_words WHERE user_id=$1 ORDER BY lower(word)")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	words := []string{}
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return words, nil
+}
+
+// AddDictionaryWords skips words already in the dictionary in any case and returns the number of added ones
+func (s *Storage) AddDictionaryWords(ctx context.Context, userID int64, words []string) (int64, error) {
+	const op = "storage.postgres.AddDictionaryWords"
+
+	stmt, err := s.db.Prepare(`INSERT INTO dictionary_words(user_id, word, created_at)
+		SELECT $1, w, $3 FROM unnest($2::text[]) AS w ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, userID, pq.Array(words), time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	added, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return added, nil
+}
+
+func (s *Storage) DeleteDictionaryWord(ctx context.Context, userID int64, word string) error {
+	const op = "storage.postgres.DeleteDictionaryWord"
+
+	stmt, err := s.db.Prepare("DELETE FROM dictionary_words WHERE user_id=$1 AND lower(word)=lower($2)")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, userID, word)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrWordNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/lib/keyring"
+)
+
+// maxCachedDataKeys bounds the cache of unwrapped data keys, a key is needed by every request of its user
+const maxCachedDataKeys = 10000
+
+var ErrEncryptionDisabled = errors.New("note is encrypted but no keyring is configured")
+
+type cachedKey struct {
+	id  int64
+	key []byte
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sealText encrypts the text with the current data key of the user.
+// Without a keyring the text is returned as is with an empty key id.
+func (s *Storage) sealText(ctx context.Context, q querier, userID int64, text string) (string, sql.NullInt64, error) {
+	sealed, keyID, err := s.sealTexts(ctx, q, userID, text)
+	if err != nil {
+		return "", sql.NullInt64{}, err
+	}
+
+	return sealed[0], keyID, nil
+}
+
+// sealTexts encrypts texts stored in one row, they share the key id column
+func (s *Storage) sealTexts(ctx context.Context, q querier, userID int64, texts ...string) ([]string, sql.NullInt64, error) {
+	if s.keyring == nil {
+		return texts, sql.NullInt64{}, nil
+	}
+
+	keyID, key, err := s.currentDataKey(ctx, q, userID)
+	if err != nil {
+		return nil, sql.NullInt64{}, err
+	}
+
+	sealed := make([]string, len(texts))
+	for i, text := range texts {
+		sealed[i], err = sealWithKey(key, userID, text)
+		if err != nil {
+			return nil, sql.NullInt64{}, err
+		}
+	}
+
+	return sealed, sql.NullInt64{Int64: keyID, Valid: true}, nil
+}
+
+func sealWithKey(key []byte, userID int64, text string) (string, error) {
+	sealed, err := keyring.Seal(key, []byte(text), textAAD(userID))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openText decrypts the text sealed by sealText. Rows without a key id are plaintext.
+func (s *Storage) openText(ctx context.Context, q querier, userID int64, text string, keyID sql.NullInt64) (string, error) {
+	if !keyID.Valid {
+		return text, nil
+	}
+
+	if s.keyring == nil {
+		return "", ErrEncryptionDisabled
+	}
+
+	key, err := s.dataKey(ctx, q, keyID.Int64, userID)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := keyring.Open(key, sealed, textAAD(userID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+// currentDataKey returns the newest data key of the user, creating one if the user has none.
+// The first key is created under a lock of the user, so concurrent first saves share it.
+func (s *Storage) currentDataKey(ctx context.Context, q querier, userID int64) (int64, []byte, error) {
+	keyID, key, err := s.newestDataKey(ctx, q, userID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return keyID, key, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", lockDataKeys, userID); err != nil {
+		return 0, nil, err
+	}
+
+	keyID, key, err = s.newestDataKey(ctx, tx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		keyID, key, err = s.newDataKey(ctx, tx, userID)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+
+	return keyID, key, nil
+}
+
+// newestDataKey returns sql.ErrNoRows if the user has no data key
+func (s *Storage) newestDataKey(ctx context.Context, q querier, userID int64) (int64, []byte, error) {
+	var keyID int64
+	var version int
+	var wrapped []byte
+
+	err := q.QueryRowContext(ctx, "SELECT id, master_key_version, wrapped_key FROM data_keys WHERE user_id=$1 ORDER BY id DESC LIMIT 1", userID).
+		Scan(&keyID, &version, &wrapped)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if key, ok := s.cachedDataKey(keyID); ok {
+		return keyID, key, nil
+	}
+
+	key, err := s.keyring.Unwrap(version, wrapped, keyAAD(userID))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.cacheDataKey(keyID, key)
+
+	return keyID, key, nil
+}
+
+// newDataKey generates a data key for the user and stores it wrapped with the current master key.
+func (s *Storage) newDataKey(ctx context.Context, q querier, userID int64) (int64, []byte, error) {
+	key, err := keyring.NewDataKey()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	version, wrapped, err := s.keyring.Wrap(key, keyAAD(userID))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var keyID int64
+	err = q.QueryRowContext(ctx, "INSERT INTO data_keys(user_id, master_key_version, wrapped_key, created_at) VALUES($1, $2, $3, $4) RETURNING id",
+		userID, version, wrapped, time.Now()).Scan(&keyID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.cacheDataKey(keyID, key)
+
+	return keyID, key, nil
+}
+
+func (s *Storage) dataKey(ctx context.Context, q querier, keyID, userID int64) ([]byte, error) {
+	if key, ok := s.cachedDataKey(keyID); ok {
+		return key, nil
+	}
+
+	var version int
+	var wrapped []byte
+
+	err := q.QueryRowContext(ctx, "SELECT master_key_version, wrapped_key FROM data_keys WHERE id=$1 AND user_id=$2", keyID, userID).
+		Scan(&version, &wrapped)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("key %d: %w", keyID, ErrDataKeyNotFound)
+		}
+
+		return nil, err
+	}
+
+	key, err := s.keyring.Unwrap(version, wrapped, keyAAD(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheDataKey(keyID, key)
+
+	return key, nil
+}
+
+func (s *Storage) cachedDataKey(keyID int64) ([]byte, bool) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	elem, ok := s.dataKeys[keyID]
+	if !ok {
+		return nil, false
+	}
+
+	s.keyOrder.MoveToFront(elem)
+
+	return elem.Value.(*cachedKey).key, true
+}
+
+// cacheDataKey evicts the least recently used key when the cache is full
+func (s *Storage) cacheDataKey(keyID int64, key []byte) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	if elem, ok := s.dataKeys[keyID]; ok {
+		s.keyOrder.MoveToFront(elem)
+		return
+	}
+
+	s.dataKeys[keyID] = s.keyOrder.PushFront(&cachedKey{id: keyID, key: key})
+
+	if s.keyOrder.Len() > maxCachedDataKeys {
+		oldest := s.keyOrder.Back()
+		s.keyOrder.Remove(oldest)
+		delete(s.dataKeys, oldest.Value.(*cachedKey).id)
+	}
+}
+
+// keyAAD binds a wrapped data key to its owner
+func keyAAD(userID int64) []byte {
+	return []byte("data-key:" + strconv.FormatInt(userID, 10))
+}
+
+// textAAD binds an encrypted text to its owner, so rows can't be moved between users
+func textAAD(userID int64) []byte {
+	return []byte("note:" + strconv.FormatInt(userID, 10))
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"container/list"
+	"testing"
+)
+
+func TestDataKeyCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	s := &Storage{dataKeys: make(map[int64]*list.Element), keyOrder: list.New()}
+
+	for id := int64(1); id <= maxCachedDataKeys; id++ {
+		s.cacheDataKey(id, []byte{byte(id)})
+	}
+
+	// the first key is used again, so the second one is the least recently used
+	if _, ok := s.cachedDataKey(1); !ok {
+		t.Fatal("key 1 is not cached")
+	}
+
+	s.cacheDataKey(maxCachedDataKeys+1, []byte{0})
+
+	if len(s.dataKeys) != maxCachedDataKeys {
+		t.Errorf("cache holds %d keys, want %d", len(s.dataKeys), maxCachedDataKeys)
+	}
+
+	if _, ok := s.cachedDataKey(2); ok {
+		t.Error("key 2 is not evicted")
+	}
+
+	for _, id := range []int64{1, 3, maxCachedDataKeys + 1} {
+		if _, ok := s.cachedDataKey(id); !ok {
+			t.Errorf("key %d is evicted", id)
+		}
+	}
+}
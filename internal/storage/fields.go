@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+func (s *Storage) SaveFieldDefinition(ctx context.Context, userID int64, field models.FieldDefinition) (int64, error) {
+	const op = "storage.postgres.SaveFieldDefinition"
+
+	stmt, err := s.db.Prepare(`INSERT INTO field_definitions(user_id, name, type, enum_values, required, created_at)
+		VALUES($1, $2, $3, $4, $5, $6) RETURNING id`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, userID, field.Name, field.Type, pq.Array(nonNilTags(field.Enum)), field.Required, time.Now()).Scan(&id)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, fmt.Errorf("%s: %w", op, ErrFieldExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetFieldDefinitions(ctx context.Context, userID int64) ([]models.FieldDefinition, error) {
+	const op = "storage.postgres.GetFieldDefinitions"
+
+	stmt, err := s.db.Prepare("SELECT id, name, type, enum_values, required, created_at FROM field_definitions WHERE user_id=$1 ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	fields := []models.FieldDefinition{}
+	for rows.Next() {
+		var field models.FieldDefinition
+
+		err = rows.Scan(&field.ID, &field.Name, &field.Type, pq.Array(&field.Enum), &field.Required, &field.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, rows.Err()
+}
+
+// DeleteFieldDefinition removes the definition, values already stored in notes are kept
+func (s *Storage) DeleteFieldDefinition(ctx context.Context, userID, fieldID int64) error {
+	const op = "storage.postgres.DeleteFieldDefinition"
+
+	stmt, err := s.db.Prepare("DELETE FROM field_definitions WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, fieldID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrFieldNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func (s *Storage) GetNoteFingerprints(ctx context.Context, userID int64) ([]models.Fingerprint, error) {
+	const op = "storage.postgres.GetNoteFingerprints"
+
+	stmt, err := s.db.Prepare("SELECT id, fingerprint FROM notes WHERE user_id=$1 AND deleted_at IS NULL AND fingerprint IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var fingerprints []models.Fingerprint
+	for rows.Next() {
+		var fp models.Fingerprint
+		var hash int64
+
+		err = rows.Scan(&fp.NoteID, &hash)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		fp.Hash = uint64(hash)
+		fingerprints = append(fingerprints, fp)
+	}
+
+	return fingerprints, rows.Err()
+}
+
+// GetUsersWithoutFingerprints returns users having notes created before fingerprints were introduced
+func (s *Storage) GetUsersWithoutFingerprints(ctx context.Context, limit int) ([]int64, error) {
+	const op = "storage.postgres.GetUsersWithoutFingerprints"
+
+	stmt, err := s.db.Prepare("SELECT DISTINCT user_id FROM notes WHERE user_id IS NOT NULL AND deleted_at IS NULL AND fingerprint IS NULL LIMIT $1")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userIDs, nil
+}
+
+// GetNotesWithoutFingerprint returns notes created before fingerprints were introduced, oldest first
+func (s *Storage) GetNotesWithoutFingerprint(ctx context.Context, userID int64, limit int) ([]models.Note, error) {
+	const op = "storage.postgres.GetNotesWithoutFingerprint"
+
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND deleted_at IS NULL AND fingerprint IS NULL ORDER BY id LIMIT $2")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+func (s *Storage) SaveFingerprint(ctx context.Context, userID, noteID int64, fingerprint uint64) error {
+	const op = "storage.postgres.SaveFingerprint"
+
+	stmt, err := s.db.Prepare("UPDATE notes SET fingerprint=$1 WHERE id=$2 AND user_id=$3")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, int64(fingerprint), noteID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
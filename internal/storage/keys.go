@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// RewrapDataKeys re-encrypts data keys wrapped with outdated master keys under the current one.
+// Keys are processed in short transactions, so the application can keep serving requests.
+func (s *Storage) RewrapDataKeys(ctx context.Context, batchSize int) (int, error) {
+	const op = "storage.postgres.RewrapDataKeys"
+
+	if s.keyring == nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrEncryptionDisabled)
+	}
+
+	total := 0
+	for {
+		n, err := s.rewrapBatch(ctx, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if n == 0 {
+			return total, nil
+		}
+
+		total += n
+	}
+}
+
+func (s *Storage) rewrapBatch(ctx context.Context, batchSize int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, user_id, master_key_version, wrapped_key FROM data_keys
+		WHERE master_key_version<>$1 ORDER BY id LIMIT $2 FOR UPDATE`, s.keyring.Current(), batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type dataKey struct {
+		id, userID int64
+		version    int
+		wrapped    []byte
+	}
+
+	var keys []dataKey
+	for rows.Next() {
+		var k dataKey
+		if err := rows.Scan(&k.id, &k.userID, &k.version, &k.wrapped); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		keys = append(keys, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		key, err := s.keyring.Unwrap(k.version, k.wrapped, keyAAD(k.userID))
+		if err != nil {
+			return 0, fmt.Errorf("key %d: %w", k.id, err)
+		}
+
+		version, wrapped, err := s.keyring.Wrap(key, keyAAD(k.userID))
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE data_keys SET master_key_version=$1, wrapped_key=$2 WHERE id=$3", version, wrapped, k.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), tx.Commit()
+}
+
+// ReencryptNotes generates a fresh data key for every user and re-encrypts the user's notes with it,
+// including notes stored before encryption was enabled. Old data keys are kept, so notes that
+// are not migrated yet stay readable while the command is running.
+func (s *Storage) ReencryptNotes(ctx context.Context, batchSize int) (int, error) {
+	const op = "storage.postgres.ReencryptNotes"
+
+	if s.keyring == nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrEncryptionDisabled)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT user_id FROM notes WHERE user_id IS NOT NULL")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var users []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		users = append(users, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	total := 0
+	for _, userID := range users {
+		keyID, key, err := s.newDataKey(ctx, s.db, userID)
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for {
+			n, err := s.reencryptBatch(ctx, userID, keyID, key, batchSize)
+			if err != nil {
+				return total, fmt.Errorf("%s: user %d: %w", op, userID, err)
+			}
+
+			if n == 0 {
+				break
+			}
+
+			total += n
+		}
+	}
+
+	return total, nil
+}
+
+func (s *Storage) reencryptBatch(ctx context.Context, userID, keyID int64, key []byte, batchSize int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, note, key_id, summary FROM notes
+		WHERE user_id=$1 AND (key_id IS NULL OR key_id<>$2) ORDER BY id LIMIT $3 FOR UPDATE`, userID, keyID, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	type encryptedNote struct {
+		id      int64
+		note    string
+		keyID   sql.NullInt64
+		summary sql.NullString
+	}
+
+	var notes []encryptedNote
+	for rows.Next() {
+		var n encryptedNote
+		if err := rows.Scan(&n.id, &n.note, &n.keyID, &n.summary); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		notes = append(notes, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, n := range notes {
+		plain, err := s.openText(ctx, tx, userID, n.note, n.keyID)
+		if err != nil {
+			return 0, fmt.Errorf("note %d: %w", n.id, err)
+		}
+
+		sealed, err := sealWithKey(key, userID, plain)
+		if err != nil {
+			return 0, err
+		}
+
+		summary := n.summary
+		if summary.Valid {
+			plainSummary, err := s.openText(ctx, tx, userID, summary.String, n.keyID)
+			if err != nil {
+				return 0, fmt.Errorf("note %d summary: %w", n.id, err)
+			}
+
+			summary.String, err = sealWithKey(key, userID, plainSummary)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		// terms of plaintext notes are not blinded, the note is indexed again after migration
+		if !n.keyID.Valid {
+			_, err = tx.ExecContext(ctx, "DELETE FROM note_terms WHERE note_id=$1", n.id)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		// counters of notes encrypted before the stats migration couldn't be computed in SQL
+		_, err = tx.ExecContext(ctx, `UPDATE notes SET note=$1, key_id=$2, summary=$3, char_count=$4, word_count=$5,
+			terms_indexed=terms_indexed AND $6 WHERE id=$7`,
+			sealed, keyID, summary, utf8.RuneCountInString(plain), len(strings.Fields(plain)), n.keyID.Valid, n.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(notes), tx.Commit()
+}
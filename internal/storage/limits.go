@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Limits exceeded by LimitError
+const (
+	LimitNotes = "notes"
+	LimitBytes = "bytes"
+)
+
+// Classes of advisory locks, the second key of a lock is the user id
+const (
+	lockUsage = iota + 1
+	lockDataKeys
+)
+
+// LimitError is returned when a saved note doesn't fit into a limit of the user
+type LimitError struct {
+	Limit string
+	Max   int64
+	Used  int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit is %d, used %d", e.Limit, e.Max, e.Used)
+}
+
+// WithLimits enforces the number of notes and the total size of notes of a user, 0 means unlimited
+func WithLimits(maxNotes, maxBytes int64) Option {
+	return func(s *Storage) {
+		s.maxNotes = maxNotes
+		s.maxBytes = maxBytes
+	}
+}
+
+// checkLimits verifies that the note of size bytes fits into the limits, noteID is 0 for a new note.
+// The usage of the user stays locked until the end of the transaction, so concurrent saves
+// can't exceed the limits together. A note may shrink even if the user is already over the limit.
+func (s *Storage) checkLimits(ctx context.Context, tx *sql.Tx, userID, noteID, size int64) error {
+	newNote := noteID == 0
+	if !(newNote && s.maxNotes > 0) && s.maxBytes <= 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", lockUsage, userID); err != nil {
+		return err
+	}
+
+	var notes, bytes, noteBytes int64
+	err := tx.QueryRowContext(ctx, `SELECT count(*), COALESCE(sum(size_bytes), 0), COALESCE(sum(size_bytes) FILTER (WHERE id=$2), 0)
+		FROM notes WHERE user_id=$1 AND deleted_at IS NULL`, userID, noteID).Scan(&notes, &bytes, &noteBytes)
+	if err != nil {
+		return err
+	}
+
+	if newNote && s.maxNotes > 0 && notes+1 > s.maxNotes {
+		return &LimitError{Limit: LimitNotes, Max: s.maxNotes, Used: notes}
+	}
+
+	if s.maxBytes > 0 && size > noteBytes && bytes-noteBytes+size > s.maxBytes {
+		return &LimitError{Limit: LimitBytes, Max: s.maxBytes, Used: bytes}
+	}
+
+	return nil
+}
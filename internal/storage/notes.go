@@ -2,35 +2,243 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/lib/pq"
 )
 
-func (s *Storage) SaveNote(ctx context.Context, note models.Note) (int64, error) {
+// noteColumns are scanned by scanNotes
+const noteColumns = "id, note, key_id, summary, tags, notebook, pinned, fields, source, daily_date, created_at, updated_at"
+
+// SaveNote inserts the note, a non-nil revision of its earlier text is saved in the same transaction
+func (s *Storage) SaveNote(ctx context.Context, note models.Note, revision *models.NoteRevision) (int64, int64, error) {
 	const op = "storage.postgres.SaveNote"
 
-	stmt, err := s.db.Prepare("INSERT INTO notes(note, user_id, created_at) VALUES($1, $2, $3) RETURNING id")
+	sealed, keyID, err := s.sealTexts(ctx, s.db, note.UserID, noteTexts(note, revision)...)
 	if err != nil {
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	row := stmt.QueryRowContext(ctx, note.Note, note.UserID, time.Now())
+	fields, err := marshalFields(note.Fields)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := s.checkLimits(ctx, tx, note.UserID, 0, int64(len(note.Note))); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	row := tx.QueryRowContext(ctx, `INSERT INTO notes(note, key_id, summary, size_bytes, char_count, word_count, fingerprint,
+		tags, notebook, pinned, fields, source, daily_date, user_id, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`,
+		sealed[0], keyID, sealed[1], len(note.Note), utf8.RuneCountInString(note.Note), len(strings.Fields(note.Note)),
+		int64(note.Fingerprint), pq.Array(nonNilTags(note.Tags)), note.Notebook, note.Pinned, fields, note.Source,
+		sql.NullString{String: note.DailyDate, Valid: note.DailyDate != ""}, note.UserID, time.Now())
 
 	var insertedID int64
 	err = row.Scan(&insertedID)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, 0, fmt.Errorf("%s: %w", op, ErrDailyNoteExists)
+		}
+
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var revisionID int64
+	if revision != nil {
+		revisionID, err = insertRevision(ctx, tx, insertedID, *revision, sealed[2], keyID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return insertedID, revisionID, nil
+}
+
+// UpdateNote replaces the text and attributes of the user note, a non-nil revision of the earlier
+// text is saved in the same transaction
+func (s *Storage) UpdateNote(ctx context.Context, note models.Note, revision *models.NoteRevision) (int64, error) {
+	const op = "storage.postgres.UpdateNote"
+
+	sealed, keyID, err := s.sealTexts(ctx, s.db, note.UserID, noteTexts(note, revision)...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields, err := marshalFields(note.Fields)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := s.checkLimits(ctx, tx, note.UserID, note.ID, int64(len(note.Note))); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE notes SET note=$1, key_id=$2, summary=$3, size_bytes=$4, char_count=$5, word_count=$6,
+		fingerprint=$7, tags=$8, notebook=$9, pinned=$10, fields=$11, updated_at=$12, terms_indexed=FALSE
+		WHERE id=$13 AND user_id=$14 AND deleted_at IS NULL`,
+		sealed[0], keyID, sealed[1], len(note.Note), utf8.RuneCountInString(note.Note), len(strings.Fields(note.Note)),
+		int64(note.Fingerprint), pq.Array(nonNilTags(note.Tags)), note.Notebook, note.Pinned, fields, time.Now(), note.ID, note.UserID)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return insertedID, nil
+	if err := expectAffected(res, ErrNoteNotFound); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var revisionID int64
+	if revision != nil {
+		revisionID, err = insertRevision(ctx, tx, note.ID, *revision, sealed[2], keyID)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revisionID, nil
+}
+
+// noteTexts lists texts of the note to seal with one data key, the revision text goes last
+func noteTexts(note models.Note, revision *models.NoteRevision) []string {
+	texts := []string{note.Note, note.Summary}
+	if revision != nil {
+		texts = append(texts, revision.Note)
+	}
+
+	return texts
+}
+
+// UpdateNoteAttributes changes only tags, notebook and pin state of the note
+func (s *Storage) UpdateNoteAttributes(ctx context.Context, note models.Note) error {
+	const op = "storage.postgres.UpdateNoteAttributes"
+
+	stmt, err := s.db.Prepare("UPDATE notes SET tags=$1, notebook=$2, pinned=$3 WHERE id=$4 AND user_id=$5 AND deleted_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, pq.Array(nonNilTags(note.Tags)), note.Notebook, note.Pinned, note.ID, note.UserID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrNoteNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteNote marks the note as deleted, it can be restored later
+func (s *Storage) DeleteNote(ctx context.Context, userID, noteID int64) error {
+	const op = "storage.postgres.DeleteNote"
+
+	stmt, err := s.db.Prepare("UPDATE notes SET deleted_at=$1 WHERE id=$2 AND user_id=$3 AND deleted_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, time.Now(), noteID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrNoteNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RestoreNote(ctx context.Context, userID, noteID int64) error {
+	const op = "storage.postgres.RestoreNote"
+
+	stmt, err := s.db.Prepare("UPDATE notes SET deleted_at=NULL WHERE id=$1 AND user_id=$2 AND deleted_at IS NOT NULL")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, noteID, userID)
+	if err != nil {
+		// a journal note was created for the day after this one was deleted
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, ErrDailyNoteExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrNoteNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetNote(ctx context.Context, userID, noteID int64) (models.Note, error) {
+	const op = "storage.postgres.GetNote"
+
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE id=$1 AND user_id=$2 AND deleted_at IS NULL")
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, noteID, userID)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(notes) == 0 {
+		return models.Note{}, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+	}
+
+	return notes[0], nil
 }
 
 func (s *Storage) GetNotesByUserId(ctx context.Context, userID int64) ([]models.Note, error) {
 	const op = "storage.postgres.GetNotesByUserId"
 
-	stmt, err := s.db.Prepare("SELECT id, note, created_at FROM notes WHERE user_id=$1")
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND deleted_at IS NULL ORDER BY id")
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -41,16 +249,9 @@ func (s *Storage) GetNotesByUserId(ctx context.Context, userID int64) ([]models.
 	}
 	defer rows.Close()
 
-	var notes []models.Note
-	for rows.Next() {
-		var note models.Note
-
-		err = rows.Scan(&note.ID, &note.Note, &note.CreatedAt)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
-
-		notes = append(notes, note)
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return notes, nil
@@ -61,7 +262,7 @@ func (s *Storage) GetNotesPageByUserId(ctx context.Context, userID int64, page,
 
 	offset := (page - 1) * limit
 
-	stmt, err := s.db.Prepare("SELECT id, note, created_at FROM notes WHERE user_id=$1 LIMIT $2 OFFSET $3")
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND deleted_at IS NULL ORDER BY id LIMIT $2 OFFSET $3")
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -72,17 +273,177 @@ func (s *Storage) GetNotesPageByUserId(ctx context.Context, userID int64, page,
 	}
 	defer rows.Close()
 
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// GetNotesAfterId returns notes of the user with ids greater than afterID in the order of ids
+func (s *Storage) GetNotesAfterId(ctx context.Context, userID, afterID int64, limit int) ([]models.Note, error) {
+	const op = "storage.postgres.GetNotesAfterId"
+
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND id>$2 AND deleted_at IS NULL ORDER BY id LIMIT $3")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// FindNotes returns notes of the user matching the filter. Field values are matched
+// by jsonb containment, so they must already have the JSON type of their definition.
+func (s *Storage) FindNotes(ctx context.Context, userID int64, filter models.NoteFilter) ([]models.Note, error) {
+	const op = "storage.postgres.FindNotes"
+
+	q := newQueryBuilder()
+	q.where("user_id=" + q.arg(userID))
+	q.where("deleted_at IS NULL")
+
+	if len(filter.Fields) > 0 {
+		fields, err := marshalFields(filter.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		q.where("fields @> " + q.arg(fields) + "::jsonb")
+	}
+
+	if filter.HasSpellingErrors {
+		q.where(`EXISTS (SELECT 1 FROM note_spelling_errors e
+			WHERE e.note_id=notes.id AND e.revision_id IS NULL AND NOT e.dismissed)`)
+	}
+
+	order := "id"
+	if filter.SortField != "" {
+		direction := "ASC"
+		if filter.SortDesc {
+			direction = "DESC"
+		}
+
+		order = "fields->" + q.arg(filter.SortField) + " " + direction + " NULLS LAST, id"
+	}
+
+	query := "SELECT " + noteColumns + " FROM notes WHERE " + q.conditions() + " ORDER BY " + order
+	if filter.Page > 0 && filter.Limit > 0 {
+		query += " LIMIT " + q.arg(filter.Limit) + " OFFSET " + q.arg((filter.Page-1)*filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+func (s *Storage) GetNotesByIds(ctx context.Context, userID int64, ids []int64) ([]models.Note, error) {
+	const op = "storage.postgres.GetNotesByIds"
+
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND deleted_at IS NULL AND id = ANY($2) ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// scanNotes reads rows of noteColumns decrypting note bodies
+func (s *Storage) scanNotes(ctx context.Context, rows *sql.Rows, userID int64) ([]models.Note, error) {
 	var notes []models.Note
 	for rows.Next() {
 		var note models.Note
+		var keyID sql.NullInt64
+		var summary sql.NullString
+		var dailyDate, updatedAt sql.NullTime
+		var fields []byte
 
-		err = rows.Scan(&note.ID, &note.Note, &note.CreatedAt)
+		err := rows.Scan(&note.ID, &note.Note, &keyID, &summary, pq.Array(&note.Tags), &note.Notebook, &note.Pinned, &fields, &note.Source,
+			&dailyDate, &note.CreatedAt, &updatedAt)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+			return nil, err
+		}
+
+		if err := json.Unmarshal(fields, &note.Fields); err != nil {
+			return nil, err
+		}
+
+		note.Note, err = s.openText(ctx, s.db, userID, note.Note, keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		if summary.Valid {
+			note.Summary, err = s.openText(ctx, s.db, userID, summary.String, keyID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if dailyDate.Valid {
+			note.DailyDate = dailyDate.Time.Format(models.DateLayout)
+		}
+
+		if updatedAt.Valid {
+			note.UpdatedAt = &updatedAt.Time
 		}
 
 		notes = append(notes, note)
 	}
 
-	return notes, nil
+	return notes, rows.Err()
+}
+
+// marshalFields encodes fields as a string, pq would send []byte as bytea
+func marshalFields(fields map[string]interface{}) (string, error) {
+	if fields == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+
+	return tags
 }
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func (s *Storage) SaveNotification(ctx context.Context, n models.Notification) (int64, error) {
+	const op = "storage.postgres.SaveNotification"
+
+	stmt, err := s.db.Prepare(`INSERT INTO notifications(user_id, kind, actor_id, note_id, comment_id, message, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, n.UserID, n.Kind, nullID(n.ActorID), nullID(n.NoteID), nullID(n.CommentID), n.Message, n.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetNotifications returns notifications of the user, newest first
+func (s *Storage) GetNotifications(ctx context.Context, userID int64, filter models.NotificationFilter) ([]models.Notification, error) {
+	const op = "storage.postgres.GetNotifications"
+
+	q := newQueryBuilder()
+	q.where("user_id=" + q.arg(userID))
+	if filter.UnreadOnly {
+		q.where("read_at IS NULL")
+	}
+
+	query := `SELECT id, user_id, kind, actor_id, note_id, comment_id, message, read_at, created_at
+		FROM notifications WHERE ` + q.conditions() + " ORDER BY id DESC"
+	if filter.Page > 0 && filter.Limit > 0 {
+		query += " LIMIT " + q.arg(filter.Limit) + " OFFSET " + q.arg((filter.Page-1)*filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		var actorID, noteID, commentID sql.NullInt64
+		var readAt sql.NullTime
+
+		err = rows.Scan(&n.ID, &n.UserID, &n.Kind, &actorID, &noteID, &commentID, &n.Message, &readAt, &n.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		n.ActorID = actorID.Int64
+		n.NoteID = noteID.Int64
+		n.CommentID = commentID.Int64
+		n.Read = readAt.Valid
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (s *Storage) CountUnreadNotifications(ctx context.Context, userID int64) (int64, error) {
+	const op = "storage.postgres.CountUnreadNotifications"
+
+	stmt, err := s.db.Prepare("SELECT COUNT(*) FROM notifications WHERE user_id=$1 AND read_at IS NULL")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var count int64
+	if err := stmt.QueryRowContext(ctx, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+func (s *Storage) MarkNotificationRead(ctx context.Context, userID, notificationID int64) error {
+	const op = "storage.postgres.MarkNotificationRead"
+
+	stmt, err := s.db.Prepare("UPDATE notifications SET read_at=COALESCE(read_at, $1) WHERE id=$2 AND user_id=$3")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, time.Now(), notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrNotificationNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead returns the number of notifications marked as read
+func (s *Storage) MarkAllNotificationsRead(ctx context.Context, userID int64) (int64, error) {
+	const op = "storage.postgres.MarkAllNotificationsRead"
+
+	stmt, err := s.db.Prepare("UPDATE notifications SET read_at=$1 WHERE user_id=$2 AND read_at IS NULL")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, time.Now(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+// GetNotificationPreferences returns defaults if the user has not saved preferences
+func (s *Storage) GetNotificationPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error) {
+	const op = "storage.postgres.GetNotificationPreferences"
+
+	stmt, err := s.db.Prepare("SELECT mentions, in_app, email, webhook_url FROM notification_preferences WHERE user_id=$1")
+	if err != nil {
+		return models.NotificationPreferences{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var p models.NotificationPreferences
+	err = stmt.QueryRowContext(ctx, userID).Scan(&p.Mentions, &p.InApp, &p.Email, &p.WebhookURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DefaultNotificationPreferences(), nil
+		}
+
+		return models.NotificationPreferences{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return p, nil
+}
+
+func (s *Storage) SaveNotificationPreferences(ctx context.Context, userID int64, p models.NotificationPreferences) error {
+	const op = "storage.postgres.SaveNotificationPreferences"
+
+	stmt, err := s.db.Prepare(`INSERT INTO notification_preferences(user_id, mentions, in_app, email, webhook_url)
+		VALUES($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET mentions=EXCLUDED.mentions, in_app=EXCLUDED.in_app,
+			email=EXCLUDED.email, webhook_url=EXCLUDED.webhook_url`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, userID, p.Mentions, p.InApp, p.Email, p.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
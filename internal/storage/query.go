@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// queryBuilder collects WHERE conditions of a dynamic query together with their
+// positional arguments, values are never concatenated into the SQL text
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+func newQueryBuilder() *queryBuilder {
+	return &queryBuilder{}
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *queryBuilder) arg(v any) string {
+	q.args = append(q.args, v)
+
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *queryBuilder) where(cond string) {
+	q.conds = append(q.conds, cond)
+}
+
+func (q *queryBuilder) conditions() string {
+	if len(q.conds) == 0 {
+		return "true"
+	}
+
+	return strings.Join(q.conds, " AND ")
+}
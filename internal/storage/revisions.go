@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+// insertRevision keeps the earlier text of the note sealed with the key of the note in the transaction saving it
+func insertRevision(ctx context.Context, tx *sql.Tx, noteID int64, revision models.NoteRevision, sealed string, keyID sql.NullInt64) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `INSERT INTO note_revisions(note_id, user_id, note, key_id, reason, created_at)
+		VALUES($1, $2, $3, $4, $5, $6) RETURNING id`,
+		noteID, revision.UserID, sealed, keyID, revision.Reason, revision.CreatedAt).Scan(&id)
+
+	return id, err
+}
+
+// GetRevisions returns revisions of the user note, the newest first
+func (s *Storage) GetRevisions(ctx context.Context, userID, noteID int64) ([]models.NoteRevision, error) {
+	const op = "storage.postgres.GetRevisions"
+
+	stmt, err := s.db.Prepare(`SELECT id, note_id, user_id, note, key_id, reason, created_at FROM note_revisions
+		WHERE user_id=$1 AND note_id=$2 ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	revisions := []models.NoteRevision{}
+	for rows.Next() {
+		revision, err := s.scanRevision(ctx, rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revisions, nil
+}
+
+func (s *Storage) GetRevision(ctx context.Context, userID, noteID, revisionID int64) (models.NoteRevision, error) {
+	const op = "storage.postgres.GetRevision"
+
+	stmt, err := s.db.Prepare(`SELECT id, note_id, user_id, note, key_id, reason, created_at FROM note_revisions
+		WHERE user_id=$1 AND note_id=$2 AND id=$3`)
+	if err != nil {
+		return models.NoteRevision{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	revision, err := s.scanRevision(ctx, stmt.QueryRowContext(ctx, userID, noteID, revisionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NoteRevision{}, fmt.Errorf("%s: %w", op, ErrRevisionNotFound)
+		}
+
+		return models.NoteRevision{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revision, nil
+}
+
+func (s *Storage) scanRevision(ctx context.Context, row scanner) (models.NoteRevision, error) {
+	var revision models.NoteRevision
+	var keyID sql.NullInt64
+
+	err := row.Scan(&revision.ID, &revision.NoteID, &revision.UserID, &revision.Note, &keyID, &revision.Reason, &revision.CreatedAt)
+	if err != nil {
+		return models.NoteRevision{}, err
+	}
+
+	revision.Note, err = s.openText(ctx, s.db, revision.UserID, revision.Note, keyID)
+	if err != nil {
+		return models.NoteRevision{}, err
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func (s *Storage) SaveRule(ctx context.Context, userID int64, rule models.Rule) (int64, error) {
+	const op = "storage.postgres.SaveRule"
+
+	conditions, actions, err := marshalRule(rule)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare("INSERT INTO rules(user_id, name, enabled, conditions, actions, created_at) VALUES($1, $2, $3, $4, $5, $6) RETURNING id")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, userID, rule.Name, rule.Enabled, conditions, actions, time.Now()).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) UpdateRule(ctx context.Context, userID int64, rule models.Rule) error {
+	const op = "storage.postgres.UpdateRule"
+
+	conditions, actions, err := marshalRule(rule)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare("UPDATE rules SET name=$1, enabled=$2, conditions=$3, actions=$4 WHERE id=$5 AND user_id=$6")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, rule.Name, rule.Enabled, conditions, actions, rule.ID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrRuleNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteRule(ctx context.Context, userID, ruleID int64) error {
+	const op = "storage.postgres.DeleteRule"
+
+	stmt, err := s.db.Prepare("DELETE FROM rules WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, ruleID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrRuleNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetRule(ctx context.Context, userID, ruleID int64) (models.Rule, error) {
+	const op = "storage.postgres.GetRule"
+
+	stmt, err := s.db.Prepare("SELECT id, name, enabled, conditions, actions, created_at FROM rules WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return models.Rule{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rule, err := scanRule(stmt.QueryRowContext(ctx, ruleID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Rule{}, fmt.Errorf("%s: %w", op, ErrRuleNotFound)
+		}
+
+		return models.Rule{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rule, nil
+}
+
+func (s *Storage) GetRules(ctx context.Context, userID int64) ([]models.Rule, error) {
+	const op = "storage.postgres.GetRules"
+
+	stmt, err := s.db.Prepare("SELECT id, name, enabled, conditions, actions, created_at FROM rules WHERE user_id=$1 ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	rules := []models.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (s *Storage) SaveRuleJob(ctx context.Context, userID int64, job models.RuleJob) (int64, error) {
+	const op = "storage.postgres.SaveRuleJob"
+
+	stmt, err := s.db.Prepare("INSERT INTO rule_jobs(rule_id, user_id, status, created_at) VALUES($1, $2, $3, $4) RETURNING id")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, job.RuleID, userID, job.Status, job.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// ClaimRuleJob leases the oldest pending job or a running one that is not leased by another worker
+func (s *Storage) ClaimRuleJob(ctx context.Context, now, lockedUntil time.Time) (models.RuleJob, error) {
+	const op = "storage.postgres.ClaimRuleJob"
+
+	stmt, err := s.db.Prepare(`UPDATE rule_jobs SET status=$3, locked_until=$2
+		WHERE id = (
+			SELECT id FROM rule_jobs
+			WHERE status=$4 OR (status=$3 AND (locked_until IS NULL OR locked_until < $1))
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, rule_id, user_id, status, processed, matched, error, created_at, last_note_id, locked_until`)
+	if err != nil {
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var job models.RuleJob
+	err = stmt.QueryRowContext(ctx, now, lockedUntil, models.JobRunning, models.JobPending).
+		Scan(&job.ID, &job.RuleID, &job.UserID, &job.Status, &job.Processed, &job.Matched, &job.Error, &job.CreatedAt,
+			&job.LastNoteID, &job.LockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RuleJob{}, fmt.Errorf("%s: %w", op, ErrNoJobs)
+		}
+
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return job, nil
+}
+
+// UpdateRuleJob saves the progress of the claimed job and releases the lease. A job claimed by another
+// worker after the lease expired has another lease, so the stale worker gets ErrJobNotFound.
+func (s *Storage) UpdateRuleJob(ctx context.Context, job models.RuleJob) error {
+	const op = "storage.postgres.UpdateRuleJob"
+
+	stmt, err := s.db.Prepare(`UPDATE rule_jobs SET status=$1, processed=$2, matched=$3, error=$4, finished_at=$5, last_note_id=$6,
+		locked_until=NULL WHERE id=$7 AND locked_until=$8`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, job.Status, job.Processed, job.Matched, job.Error, job.FinishedAt, job.LastNoteID, job.ID, job.LockedUntil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrJobNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetRuleJob(ctx context.Context, userID, jobID int64) (models.RuleJob, error) {
+	const op = "storage.postgres.GetRuleJob"
+
+	stmt, err := s.db.Prepare("SELECT id, rule_id, status, processed, matched, error, created_at, finished_at FROM rule_jobs WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var job models.RuleJob
+	var finishedAt sql.NullTime
+
+	err = stmt.QueryRowContext(ctx, jobID, userID).
+		Scan(&job.ID, &job.RuleID, &job.Status, &job.Processed, &job.Matched, &job.Error, &job.CreatedAt, &finishedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RuleJob{}, fmt.Errorf("%s: %w", op, ErrJobNotFound)
+		}
+
+		return models.RuleJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return job, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRule(row scanner) (models.Rule, error) {
+	var rule models.Rule
+	var conditions, actions []byte
+
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Enabled, &conditions, &actions, &rule.CreatedAt)
+	if err != nil {
+		return models.Rule{}, err
+	}
+
+	if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+		return models.Rule{}, err
+	}
+
+	if err := json.Unmarshal(actions, &rule.Actions); err != nil {
+		return models.Rule{}, err
+	}
+
+	return rule, nil
+}
+
+// marshalRule encodes jsonb columns as strings, pq would send []byte as bytea
+func marshalRule(rule models.Rule) (string, string, error) {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return "", "", err
+	}
+
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(conditions), string(actions), nil
+}
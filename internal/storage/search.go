@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	"github.com/blankspace9/notes-app/internal/lib/textrank"
+	"github.com/lib/pq"
+)
+
+// likeEscaper escapes pattern characters of ILIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *Storage) SearchNotes(ctx context.Context, userID int64, query searchquery.Node, filter models.SearchFilter) ([]models.Note, error) {
+	const op = "storage.postgres.SearchNotes"
+
+	q := newQueryBuilder()
+	q.where("user_id=" + q.arg(userID))
+	q.where("deleted_at IS NULL")
+
+	cond, err := s.compileSearch(ctx, q, userID, query, filter.Fuzzy)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	q.where(cond)
+
+	statement := "SELECT " + noteColumns + " FROM notes WHERE " + q.conditions() + " ORDER BY id DESC"
+	if filter.Page > 0 && filter.Limit > 0 {
+		statement += " LIMIT " + q.arg(filter.Limit) + " OFFSET " + q.arg((filter.Page-1)*filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, statement, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// compileSearch translates the query tree into a condition on notes, all values become query arguments
+func (s *Storage) compileSearch(ctx context.Context, q *queryBuilder, userID int64, node searchquery.Node, fuzzy bool) (string, error) {
+	switch n := node.(type) {
+	case searchquery.And:
+		return s.compileSearchList(ctx, q, userID, n.Nodes, " AND ", fuzzy)
+	case searchquery.Or:
+		return s.compileSearchList(ctx, q, userID, n.Nodes, " OR ", fuzzy)
+	case searchquery.Not:
+		cond, err := s.compileSearch(ctx, q, userID, n.Node, fuzzy)
+		if err != nil {
+			return "", err
+		}
+
+		return "NOT (" + cond + ")", nil
+	case searchquery.Text:
+		return s.compileSearchText(ctx, q, userID, n, fuzzy)
+	case searchquery.Field:
+		return compileSearchField(q, n)
+	default:
+		return "", fmt.Errorf("unsupported search node %T", node)
+	}
+}
+
+func (s *Storage) compileSearchList(ctx context.Context, q *queryBuilder, userID int64, nodes []searchquery.Node, sep string, fuzzy bool) (string, error) {
+	conds := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		cond, err := s.compileSearch(ctx, q, userID, node, fuzzy)
+		if err != nil {
+			return "", err
+		}
+
+		conds = append(conds, cond)
+	}
+
+	return "(" + strings.Join(conds, sep) + ")", nil
+}
+
+// compileSearchText matches plaintext notes by their text, so notes missing from the term index,
+// short words and stop words are found too. Words match whole words, phrases occur as is and fuzzy
+// words are matched by trigram similarity. Encrypted notes are matched through the term index by
+// indexed words only, a term without them matches no encrypted note.
+func (s *Storage) compileSearchText(ctx context.Context, q *queryBuilder, userID int64, text searchquery.Text, fuzzy bool) (string, error) {
+	terms := textrank.Words(text.Value)
+	slices.Sort(terms)
+	terms = slices.Compact(terms)
+
+	indexed := "FALSE"
+	if len(terms) > 0 {
+		blinded, err := s.blindTerms(ctx, userID, terms)
+		if err != nil {
+			return "", err
+		}
+
+		indexed = "(SELECT COUNT(*) FROM note_terms nt WHERE nt.note_id = notes.id AND nt.term = ANY(" + q.arg(pq.Array(blinded)) + ")) = " +
+			q.arg(len(blinded))
+	}
+
+	words := searchWords(text.Value)
+
+	var plain []string
+	switch {
+	case text.Phrase || len(words) == 0:
+		plain = append(plain, "note ILIKE "+q.arg("%"+likeEscaper.Replace(text.Value)+"%"))
+	case fuzzy:
+		for _, word := range words {
+			plain = append(plain, q.arg(word)+" <% note")
+		}
+	default:
+		for _, word := range words {
+			plain = append(plain, "note ~* "+q.arg(`\m`+word+`\M`))
+		}
+	}
+
+	return "((key_id IS NULL AND " + strings.Join(plain, " AND ") + ") OR (key_id IS NOT NULL AND " + indexed + "))", nil
+}
+
+// searchWords returns distinct lowercase words of the text, they consist of letters and digits only
+func searchWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	slices.Sort(words)
+
+	return slices.Compact(words)
+}
+
+func compileSearchField(q *queryBuilder, field searchquery.Field) (string, error) {
+	switch field.Name {
+	case searchquery.FieldTag:
+		return q.arg(field.Value) + " = ANY(tags)", nil
+	case searchquery.FieldNotebook:
+		return "notebook = " + q.arg(field.Value), nil
+	case searchquery.FieldPinned:
+		return "pinned = " + q.arg(field.Value == "true"), nil
+	case searchquery.FieldCreated:
+		return compileSearchDate(q, "created_at", field), nil
+	case searchquery.FieldUpdated:
+		return compileSearchDate(q, "COALESCE(updated_at, created_at)", field), nil
+	default:
+		return "", fmt.Errorf("unsupported search field %s", field.Name)
+	}
+}
+
+// compileSearchDate compares the column with the whole day of the field value
+func compileSearchDate(q *queryBuilder, column string, field searchquery.Field) string {
+	dayStart, dayEnd := field.Date, field.Date.AddDate(0, 0, 1)
+
+	switch field.Op {
+	case searchquery.OpGt:
+		return column + " >= " + q.arg(dayEnd)
+	case searchquery.OpGte:
+		return column + " >= " + q.arg(dayStart)
+	case searchquery.OpLt:
+		return column + " < " + q.arg(dayStart)
+	case searchquery.OpLte:
+		return column + " < " + q.arg(dayEnd)
+	default:
+		return "(" + column + " >= " + q.arg(dayStart) + " AND " + column + " < " + q.arg(dayEnd) + ")"
+	}
+}
+
+// SuggestNotes completes the prefix with tags, first lines of plaintext notes and indexed words.
+// Words are suggested only without encryption, otherwise the index holds blinded terms.
+func (s *Storage) SuggestNotes(ctx context.Context, userID int64, prefix string, limit int) ([]models.Suggestion, error) {
+	const op = "storage.postgres.SuggestNotes"
+
+	q := newQueryBuilder()
+	user := q.arg(userID)
+	pattern := q.arg(likeEscaper.Replace(prefix) + "%")
+	limitArg := q.arg(limit)
+
+	parts := []string{
+		`(SELECT DISTINCT ON (lower(t.tag)) ` + q.arg(models.SuggestionTag) + `::text AS kind, t.tag AS text
+			FROM notes, unnest(tags) AS t(tag)
+			WHERE user_id=` + user + ` AND deleted_at IS NULL AND t.tag ILIKE ` + pattern + `
+			ORDER BY lower(t.tag) LIMIT ` + limitArg + `)`,
+		`(SELECT DISTINCT ` + q.arg(models.SuggestionTitle) + `::text, left(split_part(note, E'\n', 1), 100)
+			FROM notes
+			WHERE user_id=` + user + ` AND deleted_at IS NULL AND key_id IS NULL AND note ILIKE ` + pattern + `
+			LIMIT ` + limitArg + `)`,
+	}
+
+	if s.keyring == nil {
+		parts = append(parts, `(SELECT `+q.arg(models.SuggestionWord)+`::text, term
+			FROM note_terms
+			WHERE user_id=`+user+` AND term LIKE `+q.arg(likeEscaper.Replace(strings.ToLower(prefix))+"%")+`
+			GROUP BY term ORDER BY COUNT(*) DESC, term LIMIT `+limitArg+`)`)
+	}
+
+	rows, err := s.db.QueryContext(ctx, strings.Join(parts, " UNION ALL ")+" LIMIT "+limitArg, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var suggestion models.Suggestion
+		if err := rows.Scan(&suggestion.Kind, &suggestion.Text); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, rows.Err()
+}
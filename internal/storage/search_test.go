@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	"github.com/lib/pq"
+)
+
+func TestCompileSearch(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name  string
+		query string
+		fuzzy bool
+		want  string
+		args  []any
+	}{
+		{
+			name:  "word",
+			query: "Draft",
+			want: "((key_id IS NULL AND note ~* $3) OR (key_id IS NOT NULL AND " +
+				"(SELECT COUNT(*) FROM note_terms nt WHERE nt.note_id = notes.id AND nt.term = ANY($1)) = $2))",
+			args: []any{pq.Array([]string{"draft"}), 1, `\mdraft\M`},
+		},
+		{
+			name:  "short word is matched in plaintext notes only",
+			query: "AI",
+			want:  "((key_id IS NULL AND note ~* $1) OR (key_id IS NOT NULL AND FALSE))",
+			args:  []any{`\mai\M`},
+		},
+		{
+			name:  "stop word is matched in plaintext notes only",
+			query: "the",
+			want:  "((key_id IS NULL AND note ~* $1) OR (key_id IS NOT NULL AND FALSE))",
+			args:  []any{`\mthe\M`},
+		},
+		{
+			name:  "number",
+			query: "100%",
+			want: "((key_id IS NULL AND note ~* $3) OR (key_id IS NOT NULL AND " +
+				"(SELECT COUNT(*) FROM note_terms nt WHERE nt.note_id = notes.id AND nt.term = ANY($1)) = $2))",
+			args: []any{pq.Array([]string{"100"}), 1, `\m100\M`},
+		},
+		{
+			name:  "punctuation",
+			query: "?!",
+			want:  "((key_id IS NULL AND note ILIKE $1) OR (key_id IS NOT NULL AND FALSE))",
+			args:  []any{"%?!%"},
+		},
+		{
+			name:  "phrase",
+			query: `"50% of the_plan"`,
+			want: "((key_id IS NULL AND note ILIKE $3) OR (key_id IS NOT NULL AND " +
+				"(SELECT COUNT(*) FROM note_terms nt WHERE nt.note_id = notes.id AND nt.term = ANY($1)) = $2))",
+			args: []any{pq.Array([]string{"plan"}), 1, `%50\% of the\_plan%`},
+		},
+		{
+			name:  "fuzzy",
+			query: "recieve",
+			fuzzy: true,
+			want: "((key_id IS NULL AND $3 <% note) OR (key_id IS NOT NULL AND " +
+				"(SELECT COUNT(*) FROM note_terms nt WHERE nt.note_id = notes.id AND nt.term = ANY($1)) = $2))",
+			args: []any{pq.Array([]string{"recieve"}), 1, "recieve"},
+		},
+		{
+			name:  "fields",
+			query: `tag:work -notebook:"Home office" OR pinned:true`,
+			want:  "(($1 = ANY(tags) AND NOT (notebook = $2)) OR pinned = $3)",
+			args:  []any{"work", "Home office", true},
+		},
+		{
+			name:  "dates",
+			query: "created:2024-01-01 updated:>2024-01-01 created:<=2024-01-01",
+			want: "((created_at >= $1 AND created_at < $2) AND COALESCE(updated_at, created_at) >= $3 AND " +
+				"created_at < $4)",
+			args: []any{day, nextDay, nextDay, nextDay},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := searchquery.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+
+			q := newQueryBuilder()
+			got, err := (&Storage{}).compileSearch(context.Background(), q, 1, node, tt.fuzzy)
+			if err != nil {
+				t.Fatalf("compileSearch(%q) error = %v", tt.query, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("compileSearch(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+
+			if !reflect.DeepEqual(q.args, tt.args) {
+				t.Errorf("compileSearch(%q) args = %#v, want %#v", tt.query, q.args, tt.args)
+			}
+		})
+	}
+}
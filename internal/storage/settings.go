@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+func (s *Storage) GetUserSettings(ctx context.Context, userID int64) (models.UserSettings, error) {
+	const op = "storage.postgres.GetUserSettings"
+
+	stmt, err := s.db.Prepare("SELECT COALESCE(timezone, ''), COALESCE(daily_template, ''), autocorrect_exclude FROM users WHERE id=$1")
+	if err != nil {
+		return models.UserSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var settings models.UserSettings
+	err = stmt.QueryRowContext(ctx, userID).Scan(&settings.Timezone, &settings.DailyTemplate, pq.Array(&settings.AutocorrectExclude))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserSettings{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return models.UserSettings{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return settings, nil
+}
+
+// SaveUserSettings stores empty values as NULL, so they follow the configured defaults
+func (s *Storage) SaveUserSettings(ctx context.Context, userID int64, settings models.UserSettings) error {
+	const op = "storage.postgres.SaveUserSettings"
+
+	stmt, err := s.db.Prepare("UPDATE users SET timezone=NULLIF($1, ''), daily_template=NULLIF($2, ''), autocorrect_exclude=$3 WHERE id=$4")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, settings.Timezone, settings.DailyTemplate, pq.Array(nonNilTags(settings.AutocorrectExclude)), userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrUserNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+// NoteOwner returns the owner of the note if the user owns it or the note is shared with him
+func (s *Storage) NoteOwner(ctx context.Context, userID, noteID int64) (int64, error) {
+	const op = "storage.postgres.NoteOwner"
+
+	stmt, err := s.db.Prepare(`SELECT n.user_id FROM notes n
+		WHERE n.id=$1 AND n.deleted_at IS NULL
+			AND (n.user_id=$2 OR EXISTS(SELECT 1 FROM note_shares sh WHERE sh.note_id=n.id AND sh.user_id=$2))`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var ownerID int64
+	err = stmt.QueryRowContext(ctx, noteID, userID).Scan(&ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoteNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ownerID, nil
+}
+
+func (s *Storage) SaveShare(ctx context.Context, noteID, userID int64) error {
+	const op = "storage.postgres.SaveShare"
+
+	stmt, err := s.db.Prepare("INSERT INTO note_shares(note_id, user_id, created_at) VALUES($1, $2, $3)")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, noteID, userID, time.Now())
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, ErrShareExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteShare(ctx context.Context, noteID, userID int64) error {
+	const op = "storage.postgres.DeleteShare"
+
+	stmt, err := s.db.Prepare("DELETE FROM note_shares WHERE note_id=$1 AND user_id=$2")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, noteID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrShareNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetShares(ctx context.Context, noteID int64) ([]models.NoteShare, error) {
+	const op = "storage.postgres.GetShares"
+
+	stmt, err := s.db.Prepare(`SELECT sh.note_id, sh.user_id, u.email, sh.created_at
+		FROM note_shares sh JOIN users u ON u.id = sh.user_id
+		WHERE sh.note_id=$1 ORDER BY sh.created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	shares := []models.NoteShare{}
+	for rows.Next() {
+		var share models.NoteShare
+
+		err = rows.Scan(&share.NoteID, &share.UserID, &share.Email, &share.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
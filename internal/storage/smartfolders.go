@@ -0,0 +1,298 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	"github.com/lib/pq"
+)
+
+func (s *Storage) SaveSmartFolder(ctx context.Context, userID int64, folder models.SmartFolder) (int64, error) {
+	const op = "storage.postgres.SaveSmartFolder"
+
+	definition, err := json.Marshal(folder.Definition)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare(`INSERT INTO smart_folders(user_id, name, definition, created_at)
+		VALUES($1, $2, $3, $4) RETURNING id`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowContext(ctx, userID, folder.Name, string(definition), time.Now()).Scan(&id)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, fmt.Errorf("%s: %w", op, ErrSmartFolderExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) UpdateSmartFolder(ctx context.Context, userID int64, folder models.SmartFolder) error {
+	const op = "storage.postgres.UpdateSmartFolder"
+
+	definition, err := json.Marshal(folder.Definition)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare("UPDATE smart_folders SET name=$1, definition=$2, updated_at=$3 WHERE id=$4 AND user_id=$5")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, folder.Name, string(definition), time.Now(), folder.ID, userID)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, ErrSmartFolderExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrSmartFolderNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteSmartFolder(ctx context.Context, userID, folderID int64) error {
+	const op = "storage.postgres.DeleteSmartFolder"
+
+	stmt, err := s.db.Prepare("DELETE FROM smart_folders WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, folderID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrSmartFolderNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetSmartFolder(ctx context.Context, userID, folderID int64) (models.SmartFolder, error) {
+	const op = "storage.postgres.GetSmartFolder"
+
+	stmt, err := s.db.Prepare("SELECT id, name, definition, created_at, updated_at FROM smart_folders WHERE id=$1 AND user_id=$2")
+	if err != nil {
+		return models.SmartFolder{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	folder, err := scanSmartFolder(stmt.QueryRowContext(ctx, folderID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SmartFolder{}, fmt.Errorf("%s: %w", op, ErrSmartFolderNotFound)
+		}
+
+		return models.SmartFolder{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return folder, nil
+}
+
+// GetSmartFolders returns smart folders of the user, notes of all folders are counted in one query
+func (s *Storage) GetSmartFolders(ctx context.Context, userID int64) ([]models.SmartFolder, error) {
+	const op = "storage.postgres.GetSmartFolders"
+
+	stmt, err := s.db.Prepare("SELECT id, name, definition, created_at, updated_at FROM smart_folders WHERE user_id=$1 ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	folders := []models.SmartFolder{}
+	for rows.Next() {
+		folder, err := scanSmartFolder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		folders = append(folders, folder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.countSmartFolderNotes(ctx, userID, folders); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return folders, nil
+}
+
+// countSmartFolderNotes sets the number of matching notes of every folder
+func (s *Storage) countSmartFolderNotes(ctx context.Context, userID int64, folders []models.SmartFolder) error {
+	if len(folders) == 0 {
+		return nil
+	}
+
+	q := newQueryBuilder()
+	user := q.arg(userID)
+
+	counts := make([]string, 0, len(folders))
+	for i, folder := range folders {
+		cond, err := s.compileSmartFolder(ctx, q, userID, folder.Definition)
+		if err != nil {
+			return err
+		}
+
+		counts = append(counts, "SELECT "+q.arg(i)+"::integer, COUNT(*) FROM notes WHERE user_id="+user+
+			" AND deleted_at IS NULL AND "+cond)
+	}
+
+	rows, err := s.db.QueryContext(ctx, strings.Join(counts, " UNION ALL "), q.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i int
+		var count int64
+		if err := rows.Scan(&i, &count); err != nil {
+			return err
+		}
+
+		folders[i].Count = count
+	}
+
+	return rows.Err()
+}
+
+// GetSmartFolderNotes evaluates the saved definition of the folder returning a page of matching notes
+func (s *Storage) GetSmartFolderNotes(ctx context.Context, userID int64, folder models.SmartFolder, page, limit int) ([]models.Note, error) {
+	const op = "storage.postgres.GetSmartFolderNotes"
+
+	direction := "ASC"
+	if folder.Definition.Order == "desc" {
+		direction = "DESC"
+	}
+
+	order := "id " + direction
+	switch folder.Definition.Sort {
+	case "created":
+		order = "created_at " + direction + ", id " + direction
+	case "updated":
+		order = "COALESCE(updated_at, created_at) " + direction + ", id " + direction
+	}
+
+	q := newQueryBuilder()
+	q.where("user_id=" + q.arg(userID))
+	q.where("deleted_at IS NULL")
+
+	cond, err := s.compileSmartFolder(ctx, q, userID, folder.Definition)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	q.where(cond)
+
+	statement := "SELECT " + noteColumns + " FROM notes WHERE " + q.conditions() +
+		" ORDER BY " + order + " LIMIT " + q.arg(limit) + " OFFSET " + q.arg((page-1)*limit)
+
+	rows, err := s.db.QueryContext(ctx, statement, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// compileSmartFolder translates the folder definition into a condition on notes. Listing counts
+// and opening a folder share it, so they always agree. The query is matched like a search.
+func (s *Storage) compileSmartFolder(ctx context.Context, q *queryBuilder, userID int64, definition models.SmartFolderDefinition) (string, error) {
+	var conds []string
+
+	if len(definition.Tags) > 0 {
+		conds = append(conds, "tags @> "+q.arg(pq.Array(definition.Tags)))
+	}
+
+	if definition.CreatedFrom != nil {
+		conds = append(conds, "created_at >= "+q.arg(*definition.CreatedFrom))
+	}
+
+	if definition.CreatedTo != nil {
+		conds = append(conds, "created_at < "+q.arg(*definition.CreatedTo))
+	}
+
+	if definition.Query != "" {
+		node, err := searchquery.Parse(definition.Query)
+		if err != nil {
+			return "", err
+		}
+
+		cond, err := s.compileSearch(ctx, q, userID, node, false)
+		if err != nil {
+			return "", err
+		}
+
+		conds = append(conds, cond)
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", nil
+	}
+
+	return "(" + strings.Join(conds, " AND ") + ")", nil
+}
+
+// scanSmartFolder reads folder columns followed by extra destinations
+func scanSmartFolder(row scanner, extra ...any) (models.SmartFolder, error) {
+	var folder models.SmartFolder
+	var definition []byte
+	var updatedAt sql.NullTime
+
+	dest := append([]any{&folder.ID, &folder.Name, &definition, &folder.CreatedAt, &updatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return models.SmartFolder{}, err
+	}
+
+	if err := json.Unmarshal(definition, &folder.Definition); err != nil {
+		return models.SmartFolder{}, err
+	}
+	folder.Definition.Upgrade()
+
+	if updatedAt.Valid {
+		folder.UpdatedAt = &updatedAt.Time
+	}
+
+	return folder, nil
+}
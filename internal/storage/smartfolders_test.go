@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/blankspace9/notes-app/internal/lib/searchquery"
+	"github.com/lib/pq"
+)
+
+func TestCompileSmartFolder(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+
+	tests := []struct {
+		name       string
+		definition models.SmartFolderDefinition
+		want       string
+		args       []any
+	}{
+		{
+			name: "empty",
+			want: "TRUE",
+		},
+		{
+			name:       "short words are not matched through the index",
+			definition: models.SmartFolderDefinition{Query: "go"},
+			want:       "(((key_id IS NULL AND note ~* $1) OR (key_id IS NOT NULL AND FALSE)))",
+			args:       []any{`\mgo\M`},
+		},
+		{
+			name: "tags, dates and query",
+			definition: models.SmartFolderDefinition{
+				Query:       "tag:done OR report",
+				Tags:        []string{"work"},
+				CreatedFrom: &from,
+				CreatedTo:   &to,
+			},
+			want: "(tags @> $1 AND created_at >= $2 AND created_at < $3 AND ($4 = ANY(tags) OR " +
+				"((key_id IS NULL AND note ~* $7) OR (key_id IS NOT NULL AND " +
+				"(SELECT COUNT(*) FROM note_terms nt WHERE nt.note_id = notes.id AND nt.term = ANY($5)) = $6))))",
+			args: []any{pq.Array([]string{"work"}), from, to, "done", pq.Array([]string{"report"}), 1, `\mreport\M`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newQueryBuilder()
+			got, err := (&Storage{}).compileSmartFolder(context.Background(), q, 1, tt.definition)
+			if err != nil {
+				t.Fatalf("compileSmartFolder() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("compileSmartFolder() = %s, want %s", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(q.args, tt.args) {
+				t.Errorf("compileSmartFolder() args = %#v, want %#v", q.args, tt.args)
+			}
+		})
+	}
+
+	_, err := (&Storage{}).compileSmartFolder(context.Background(), newQueryBuilder(), 1, models.SmartFolderDefinition{Query: "a OR"})
+
+	var queryErr *searchquery.Error
+	if !errors.As(err, &queryErr) {
+		t.Errorf("compileSmartFolder() error = %v, want *searchquery.Error", err)
+	}
+}
@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+const spellcheckJobColumns = "id, note_id, user_id, status, attempts, error, run_at, locked_until, created_at"
+
+// EnqueueSpellcheckJob schedules a check of the note text. A pending job of the note is rescheduled
+// instead, dead jobs of the note are superseded by the new one.
+func (s *Storage) EnqueueSpellcheckJob(ctx context.Context, userID, noteID int64, runAt time.Time) error {
+	const op = "storage.postgres.EnqueueSpellcheckJob"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM spellcheck_jobs WHERE note_id=$1 AND status=$2", noteID, models.JobDead)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO spellcheck_jobs(note_id, user_id, status, run_at, created_at) VALUES($1, $2, $3, $4, $4)
+		ON CONFLICT (note_id) WHERE status = 'pending' DO UPDATE SET run_at=EXCLUDED.run_at, attempts=0, error=''`,
+		noteID, userID, models.JobPending, runAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ClaimSpellcheckJob locks the next due job until lockedUntil, concurrent workers skip locked rows.
+// A running job whose lock expired, e.g. after a crash of its worker, is claimed again.
+// Jobs of a note being checked wait, so results of an older text do not overwrite newer ones.
+func (s *Storage) ClaimSpellcheckJob(ctx context.Context, now, lockedUntil time.Time) (models.SpellcheckJob, error) {
+	const op = "storage.postgres.ClaimSpellcheckJob"
+
+	stmt, err := s.db.Prepare(`UPDATE spellcheck_jobs SET status=$3, attempts=attempts+1, locked_until=$2
+		WHERE id = (
+			SELECT j.id FROM spellcheck_jobs j
+			WHERE ((j.status=$4 AND j.run_at <= $1) OR (j.status=$3 AND j.locked_until < $1))
+				AND NOT EXISTS (SELECT 1 FROM spellcheck_jobs r
+					WHERE r.note_id=j.note_id AND r.id<>j.id AND r.status=$3 AND r.locked_until >= $1)
+			ORDER BY j.run_at, j.id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + spellcheckJobColumns)
+	if err != nil {
+		return models.SpellcheckJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	job, err := scanSpellcheckJob(stmt.QueryRowContext(ctx, now, lockedUntil, models.JobRunning, models.JobPending))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SpellcheckJob{}, fmt.Errorf("%s: %w", op, ErrNoJobs)
+		}
+
+		return models.SpellcheckJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return job, nil
+}
+
+// Jobs are updated by the worker only while it holds the claim: a job claimed again after its lock
+// expired has more attempts, so the stale worker does not touch it.
+
+// CompleteSpellcheckJob deletes the finished job
+func (s *Storage) CompleteSpellcheckJob(ctx context.Context, job models.SpellcheckJob) error {
+	const op = "storage.postgres.CompleteSpellcheckJob"
+
+	stmt, err := s.db.Prepare("DELETE FROM spellcheck_jobs WHERE id=$1 AND status=$2 AND attempts=$3")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, job.ID, models.JobRunning, job.Attempts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RescheduleSpellcheckJob returns the failed job to the queue. If the note got a pending job
+// meanwhile, the failed one is dropped.
+func (s *Storage) RescheduleSpellcheckJob(ctx context.Context, job models.SpellcheckJob, jobErr string, runAt time.Time) error {
+	const op = "storage.postgres.RescheduleSpellcheckJob"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `UPDATE spellcheck_jobs j SET status=$4, error=$5, run_at=$6, locked_until=NULL
+		WHERE id=$1 AND status=$2 AND attempts=$3
+			AND NOT EXISTS (SELECT 1 FROM spellcheck_jobs p WHERE p.note_id=j.note_id AND p.status=$4)`,
+		job.ID, models.JobRunning, job.Attempts, models.JobPending, jobErr, runAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// a rescheduled job is pending, so only a job superseded by a pending one is deleted
+	_, err = tx.ExecContext(ctx, "DELETE FROM spellcheck_jobs WHERE id=$1 AND status=$2 AND attempts=$3", job.ID, models.JobRunning, job.Attempts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeadLetterSpellcheckJob keeps the job failed too many times for an admin
+func (s *Storage) DeadLetterSpellcheckJob(ctx context.Context, job models.SpellcheckJob, jobErr string) error {
+	const op = "storage.postgres.DeadLetterSpellcheckJob"
+
+	stmt, err := s.db.Prepare("UPDATE spellcheck_jobs SET status=$1, error=$2, locked_until=NULL WHERE id=$3 AND status=$4 AND attempts=$5")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, models.JobDead, jobErr, job.ID, models.JobRunning, job.Attempts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RequeueSpellcheckJob returns the dead job to the queue with all attempts. If the note
+// got a pending job meanwhile, the dead one is dropped.
+func (s *Storage) RequeueSpellcheckJob(ctx context.Context, jobID int64, runAt time.Time) error {
+	const op = "storage.postgres.RequeueSpellcheckJob"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE spellcheck_jobs j SET status=$3, attempts=0, error='', run_at=$4
+		WHERE id=$1 AND status=$2
+			AND NOT EXISTS (SELECT 1 FROM spellcheck_jobs p WHERE p.note_id=j.note_id AND p.status=$3)`,
+		jobID, models.JobDead, models.JobPending, runAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	requeued, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// a requeued job is pending, so only a dead job superseded by a pending one is deleted
+	res, err = tx.ExecContext(ctx, "DELETE FROM spellcheck_jobs WHERE id=$1 AND status=$2", jobID, models.JobDead)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	dropped, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if requeued+dropped == 0 {
+		return fmt.Errorf("%s: %w", op, ErrJobNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetSpellcheckJobs returns jobs with the status, all of them for an empty status, the newest first
+func (s *Storage) GetSpellcheckJobs(ctx context.Context, status string, limit int) ([]models.SpellcheckJob, error) {
+	const op = "storage.postgres.GetSpellcheckJobs"
+
+	stmt, err := s.db.Prepare("SELECT " + spellcheckJobColumns + " FROM spellcheck_jobs WHERE $1='' OR status=$1 ORDER BY id DESC LIMIT $2")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	jobs := []models.SpellcheckJob{}
+	for rows.Next() {
+		job, err := scanSpellcheckJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return jobs, nil
+}
+
+// CountSpellcheckJobs returns the number of jobs by status
+func (s *Storage) CountSpellcheckJobs(ctx context.Context) (map[string]int64, error) {
+	const op = "storage.postgres.CountSpellcheckJobs"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT status, count(*) FROM spellcheck_jobs GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{models.JobPending: 0, models.JobRunning: 0, models.JobDead: 0}
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return counts, nil
+}
+
+// GetNoteSpellcheckJobStatus returns the status of the latest job of the note, empty without jobs
+func (s *Storage) GetNoteSpellcheckJobStatus(ctx context.Context, noteID int64) (string, error) {
+	const op = "storage.postgres.GetNoteSpellcheckJobStatus"
+
+	stmt, err := s.db.Prepare("SELECT status FROM spellcheck_jobs WHERE note_id=$1 ORDER BY id DESC LIMIT 1")
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var status string
+	err = stmt.QueryRowContext(ctx, noteID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return status, nil
+}
+
+func scanSpellcheckJob(row scanner) (models.SpellcheckJob, error) {
+	var job models.SpellcheckJob
+	var lockedUntil sql.NullTime
+
+	err := row.Scan(&job.ID, &job.NoteID, &job.UserID, &job.Status, &job.Attempts, &job.Error, &job.RunAt, &lockedUntil, &job.CreatedAt)
+	if err != nil {
+		return models.SpellcheckJob{}, err
+	}
+
+	if lockedUntil.Valid {
+		job.LockedUntil = &lockedUntil.Time
+	}
+
+	return job, nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+// SaveSpellingErrors replaces spelling errors of the note text, revisionID 0 is the current text.
+// Words and suggestions are sealed with the data key of the owner.
+func (s *Storage) SaveSpellingErrors(ctx context.Context, userID, noteID, revisionID int64, annotations []models.SpellingAnnotation) error {
+	const op = "storage.postgres.SaveSpellingErrors"
+
+	codes := make([]int64, len(annotations))
+	positions := make([]int64, len(annotations))
+	dismissed := make([]bool, len(annotations))
+	texts := make([]string, 0, 2*len(annotations))
+	for i, annotation := range annotations {
+		suggestions, err := json.Marshal(annotation.Suggestions)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		codes[i] = int64(annotation.Code)
+		positions[i] = int64(annotation.Pos)
+		dismissed[i] = annotation.Dismissed
+		texts = append(texts, annotation.Word, string(suggestions))
+	}
+
+	sealed, keyID, err := s.sealTexts(ctx, s.db, userID, texts...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	words := make([]string, len(annotations))
+	suggestions := make([]string, len(annotations))
+	for i := range annotations {
+		words[i], suggestions[i] = sealed[2*i], sealed[2*i+1]
+	}
+
+	revision := sql.NullInt64{Int64: revisionID, Valid: revisionID != 0}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM note_spelling_errors WHERE user_id=$1 AND note_id=$2 AND revision_id IS NOT DISTINCT FROM $3",
+		userID, noteID, revision)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(annotations) > 0 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO note_spelling_errors(note_id, user_id, revision_id, code, pos, word, suggestions, key_id, dismissed)
+			SELECT $1, $2, $3, e.code, e.pos, e.word, e.suggestions, $4, e.dismissed
+			FROM unnest($5::int[], $6::int[], $7::text[], $8::text[], $9::boolean[]) AS e(code, pos, word, suggestions, dismissed)`,
+			noteID, userID, revision, keyID, pq.Array(codes), pq.Array(positions), pq.Array(words), pq.Array(suggestions), pq.Array(dismissed))
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetSpellingErrors returns spelling errors of the note text in order of positions, revisionID 0 is the current text
+func (s *Storage) GetSpellingErrors(ctx context.Context, userID, noteID, revisionID int64) ([]models.SpellingAnnotation, error) {
+	const op = "storage.postgres.GetSpellingErrors"
+
+	stmt, err := s.db.Prepare(`SELECT id, code, pos, word, suggestions, key_id, dismissed FROM note_spelling_errors
+		WHERE user_id=$1 AND note_id=$2 AND revision_id IS NOT DISTINCT FROM $3 ORDER BY pos, id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, noteID, sql.NullInt64{Int64: revisionID, Valid: revisionID != 0})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	annotations := []models.SpellingAnnotation{}
+	for rows.Next() {
+		var annotation models.SpellingAnnotation
+		var suggestions string
+		var keyID sql.NullInt64
+
+		err := rows.Scan(&annotation.ID, &annotation.Code, &annotation.Pos, &annotation.Word, &suggestions, &keyID, &annotation.Dismissed)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		annotation.Word, err = s.openText(ctx, s.db, userID, annotation.Word, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		suggestions, err = s.openText(ctx, s.db, userID, suggestions, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := json.Unmarshal([]byte(suggestions), &annotation.Suggestions); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return annotations, nil
+}
+
+// DismissSpellingError hides the spelling error of the current note text
+func (s *Storage) DismissSpellingError(ctx context.Context, userID, noteID, errorID int64) error {
+	const op = "storage.postgres.DismissSpellingError"
+
+	stmt, err := s.db.Prepare(`UPDATE note_spelling_errors SET dismissed=TRUE
+		WHERE id=$1 AND user_id=$2 AND note_id=$3 AND revision_id IS NULL`)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, errorID, userID, noteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrSpellingErrorNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
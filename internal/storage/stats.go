@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// GetStats aggregates writing statistics of the user. Days and weeks are calendar
+// periods in the given timezone, Weeks and Heatmap contain only notes created after since.
+func (s *Storage) GetStats(ctx context.Context, userID int64, timezone string, since time.Time) (models.Stats, error) {
+	const op = "storage.postgres.GetStats"
+
+	stats := models.Stats{Timezone: timezone}
+
+	// average per day is taken over calendar days since the first note
+	err := s.db.QueryRowContext(ctx, `SELECT count(*), COALESCE(sum(word_count), 0), COALESCE(sum(char_count), 0),
+		COALESCE(avg(char_count), 0),
+		COALESCE(count(*)::float8 / GREATEST((now() AT TIME ZONE $2)::date - min(created_at AT TIME ZONE $2)::date + 1, 1), 0)
+		FROM notes WHERE user_id=$1 AND deleted_at IS NULL`, userID, timezone).
+		Scan(&stats.Notes, &stats.Words, &stats.Characters, &stats.AverageLength, &stats.AverageNotesPerDay)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats.AverageNotesPerWeek = stats.AverageNotesPerDay * 7
+
+	// gaps and islands: consecutive days share the same day - row_number
+	err = s.db.QueryRowContext(ctx, `SELECT COALESCE(max(streak), 0) FROM (
+			SELECT count(*) AS streak FROM (
+				SELECT day - (row_number() OVER (ORDER BY day))::int AS grp
+				FROM (SELECT DISTINCT (created_at AT TIME ZONE $2)::date AS day FROM notes WHERE user_id=$1 AND deleted_at IS NULL) days
+			) islands GROUP BY grp
+		) streaks`, userID, timezone).Scan(&stats.LongestStreak)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats.Heatmap, err = s.periodCounts(ctx, "day", userID, timezone, since)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats.Weeks, err = s.periodCounts(ctx, "week", userID, timezone, since)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return stats, nil
+}
+
+// periodCounts groups notes by date_trunc(period) in the timezone, period is a constant
+func (s *Storage) periodCounts(ctx context.Context, period string, userID int64, timezone string, since time.Time) ([]models.PeriodCount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT date_trunc('`+period+`', created_at AT TIME ZONE $2)::date AS period,
+		count(*), COALESCE(sum(word_count), 0)
+		FROM notes WHERE user_id=$1 AND deleted_at IS NULL AND created_at >= $3 GROUP BY period ORDER BY period`, userID, timezone, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []models.PeriodCount{}
+	for rows.Next() {
+		var date time.Time
+		var count models.PeriodCount
+
+		if err := rows.Scan(&date, &count.Notes, &count.Words); err != nil {
+			return nil, err
+		}
+
+		count.Date = date.Format(dateLayout)
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
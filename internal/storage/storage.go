@@ -1,13 +1,37 @@
 package storage
 
 import (
+	"container/list"
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
+
+	"github.com/blankspace9/notes-app/internal/lib/keyring"
 )
 
 type Storage struct {
 	db *sql.DB
+
+	// keyring enables at-rest encryption of note bodies when set
+	keyring *keyring.Keyring
+	// dataKeys caches unwrapped data keys by id, keyOrder is the order of their use
+	keysMu   sync.Mutex
+	dataKeys map[int64]*list.Element
+	keyOrder *list.List
+
+	// limits of a user, 0 means unlimited
+	maxNotes int64
+	maxBytes int64
+}
+
+type Option func(*Storage)
+
+// WithKeyring enables encryption of note bodies with per-user data keys wrapped by the keyring.
+func WithKeyring(kr *keyring.Keyring) Option {
+	return func(s *Storage) {
+		s.keyring = kr
+	}
 }
 
 type PostgresConnectionInfo struct {
@@ -20,7 +44,7 @@ type PostgresConnectionInfo struct {
 }
 
 // New creates a new instance of the PostgreSQL storage.
-func New(connectionInfo PostgresConnectionInfo) (*Storage, error) {
+func New(connectionInfo PostgresConnectionInfo, opts ...Option) (*Storage, error) {
 	const op = "storage.postgres.New"
 
 	db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=%s password=%s",
@@ -33,7 +57,22 @@ func New(connectionInfo PostgresConnectionInfo) (*Storage, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Storage{db: db}, nil
+	s := &Storage{
+		db:       db,
+		dataKeys: make(map[int64]*list.Element),
+		keyOrder: list.New(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// Encrypted reports whether note bodies are encrypted at rest.
+func (s *Storage) Encrypted() bool {
+	return s.keyring != nil
 }
 
 var (
@@ -42,4 +81,46 @@ var (
 
 	ErrTokenExists   = errors.New("refresh token already exists")
 	ErrTokenNotFound = errors.New("refresh token not fount")
+
+	ErrNoteNotFound    = errors.New("note not found")
+	ErrDailyNoteExists = errors.New("daily note already exists")
+
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrJobNotFound  = errors.New("job not found")
+
+	ErrFieldExists   = errors.New("field already exists")
+	ErrFieldNotFound = errors.New("field not found")
+
+	ErrDataKeyNotFound = errors.New("data key not found")
+
+	ErrShareExists     = errors.New("note already shared with user")
+	ErrShareNotFound   = errors.New("share not found")
+	ErrCommentNotFound = errors.New("comment not found")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	ErrSmartFolderExists   = errors.New("smart folder already exists")
+	ErrSmartFolderNotFound = errors.New("smart folder not found")
+
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	ErrWordNotFound = errors.New("word not found in dictionary")
+
+	ErrSpellingErrorNotFound = errors.New("spelling error not found")
+
+	ErrNoJobs = errors.New("no jobs to run")
 )
+
+// expectAffected returns errNotFound if the statement didn't change any row
+func expectAffected(res sql.Result, errNotFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return errNotFound
+	}
+
+	return nil
+}
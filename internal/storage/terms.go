@@ -0,0 +1,273 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+	"github.com/lib/pq"
+)
+
+// SaveNoteTerms replaces the term vector of the note
+func (s *Storage) SaveNoteTerms(ctx context.Context, userID, noteID int64, weights map[string]float64) error {
+	const op = "storage.postgres.SaveNoteTerms"
+
+	terms := make([]string, 0, len(weights))
+	values := make([]float64, 0, len(weights))
+	for term, weight := range weights {
+		terms = append(terms, term)
+		values = append(values, weight)
+	}
+
+	blinded, err := s.blindTerms(ctx, userID, terms)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM note_terms WHERE note_id=$1", noteID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO note_terms(note_id, user_id, term, weight)
+		SELECT $1, $2, t.term, t.weight FROM unnest($3::text[], $4::float8[]) AS t(term, weight)
+		ON CONFLICT (note_id, term) DO NOTHING`, noteID, userID, pq.Array(blinded), pq.Array(values))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE notes SET terms_indexed=TRUE WHERE id=$1 AND user_id=$2 AND deleted_at IS NULL", noteID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := expectAffected(res, ErrNoteNotFound); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteNoteTerms removes the note from the index, e.g. when it is deleted
+func (s *Storage) DeleteNoteTerms(ctx context.Context, noteID int64) error {
+	const op = "storage.postgres.DeleteNoteTerms"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM note_terms WHERE note_id=$1", noteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE notes SET terms_indexed=FALSE WHERE id=$1", noteID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetDocumentFrequencies returns the number of indexed notes of the user containing each term
+// and the total number of indexed notes
+func (s *Storage) GetDocumentFrequencies(ctx context.Context, userID int64, terms []string) (map[string]int, int, error) {
+	const op = "storage.postgres.GetDocumentFrequencies"
+
+	blinded, err := s.blindTerms(ctx, userID, terms)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byBlinded := make(map[string]string, len(terms))
+	for i, term := range terms {
+		byBlinded[blinded[i]] = term
+	}
+
+	var docs int
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM notes WHERE user_id=$1 AND terms_indexed AND deleted_at IS NULL", userID).
+		Scan(&docs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT term, COUNT(*) FROM note_terms WHERE user_id=$1 AND term = ANY($2) GROUP BY term",
+		userID, pq.Array(blinded))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	docFreq := make(map[string]int, len(terms))
+	for rows.Next() {
+		var term string
+		var count int
+		if err := rows.Scan(&term, &count); err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		docFreq[byBlinded[term]] = count
+	}
+
+	return docFreq, docs, rows.Err()
+}
+
+// GetNotesWithoutTerms returns notes that are not indexed yet, oldest first
+func (s *Storage) GetNotesWithoutTerms(ctx context.Context, userID int64, limit int) ([]models.Note, error) {
+	const op = "storage.postgres.GetNotesWithoutTerms"
+
+	stmt, err := s.db.Prepare("SELECT " + noteColumns + " FROM notes WHERE user_id=$1 AND deleted_at IS NULL AND NOT terms_indexed ORDER BY id LIMIT $2")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	notes, err := s.scanNotes(ctx, rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return notes, nil
+}
+
+// GetUsersWithoutTerms returns users having notes that are not indexed yet
+func (s *Storage) GetUsersWithoutTerms(ctx context.Context, limit int) ([]int64, error) {
+	const op = "storage.postgres.GetUsersWithoutTerms"
+
+	stmt, err := s.db.Prepare("SELECT DISTINCT user_id FROM notes WHERE user_id IS NOT NULL AND deleted_at IS NULL AND NOT terms_indexed LIMIT $1")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userIDs, nil
+}
+
+// GetRelatedNotes scores notes sharing the heaviest queryTerms terms of the note by the dot product
+// of their unit vectors, which is the cosine similarity over the shared terms
+func (s *Storage) GetRelatedNotes(ctx context.Context, userID, noteID int64, queryTerms, limit int) ([]models.NoteScore, error) {
+	const op = "storage.postgres.GetRelatedNotes"
+
+	stmt, err := s.db.Prepare(`WITH q AS (
+			SELECT term, weight FROM note_terms WHERE note_id=$1 AND user_id=$2 ORDER BY weight DESC LIMIT $3
+		)
+		SELECT d.note_id, SUM(q.weight * d.weight) AS score
+		FROM q JOIN note_terms d ON d.user_id=$2 AND d.term=q.term AND d.note_id<>$1
+		GROUP BY d.note_id
+		ORDER BY score DESC, d.note_id
+		LIMIT $4`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, noteID, userID, queryTerms, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	scores := []models.NoteScore{}
+	for rows.Next() {
+		var score models.NoteScore
+		if err := rows.Scan(&score.NoteID, &score.Score); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+// blindTerms replaces terms with their HMAC under the index key of the user when encryption is enabled.
+// The key is derived from the first data key of the user, which survives master key rotation.
+func (s *Storage) blindTerms(ctx context.Context, userID int64, terms []string) ([]string, error) {
+	if s.keyring == nil {
+		return terms, nil
+	}
+
+	key, err := s.indexKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blinded := make([]string, len(terms))
+	for i, term := range terms {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(term))
+		blinded[i] = base64.RawStdEncoding.EncodeToString(mac.Sum(nil)[:16])
+	}
+
+	return blinded, nil
+}
+
+func (s *Storage) indexKey(ctx context.Context, userID int64) ([]byte, error) {
+	var keyID int64
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM data_keys WHERE user_id=$1 ORDER BY id LIMIT 1", userID).Scan(&keyID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		keyID, _, err = s.currentDataKey(ctx, s.db, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dataKey, err := s.dataKey(ctx, s.db, keyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, dataKey)
+	mac.Write([]byte("term-index"))
+
+	return mac.Sum(nil), nil
+}
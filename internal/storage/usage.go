@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blankspace9/notes-app/internal/domain/models"
+)
+
+func (s *Storage) GetUsage(ctx context.Context, userID int64) (models.Usage, error) {
+	const op = "storage.postgres.GetUsage"
+
+	stmt, err := s.db.Prepare("SELECT count(*), COALESCE(sum(size_bytes), 0) FROM notes WHERE user_id=$1 AND deleted_at IS NULL")
+	if err != nil {
+		return models.Usage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var usage models.Usage
+	err = stmt.QueryRowContext(ctx, userID).Scan(&usage.Notes, &usage.Bytes)
+	if err != nil {
+		return models.Usage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,2 @@
+-- one-line summary for listings, sealed with the same data key as the note
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS summary TEXT;
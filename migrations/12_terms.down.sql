@@ -0,0 +1,2 @@
+ALTER TABLE notes DROP COLUMN IF EXISTS terms_indexed;
+DROP TABLE IF EXISTS note_terms;
@@ -0,0 +1,13 @@
+-- tf-idf vectors of notes normalized to unit length. With encryption enabled
+-- terms are blinded with a per-user HMAC key, so the index doesn't reveal words.
+CREATE TABLE IF NOT EXISTS note_terms (
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    term TEXT NOT NULL,
+    weight DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (note_id, term)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_terms_user_term ON note_terms (user_id, term) INCLUDE (note_id, weight);
+
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS terms_indexed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notes_note_trgm;
+
+DROP EXTENSION IF EXISTS pg_trgm;
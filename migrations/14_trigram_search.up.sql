@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- encrypted notes can't be matched by trigrams, only plaintext ones are indexed
+CREATE INDEX IF NOT EXISTS idx_notes_note_trgm ON notes USING GIN (note gin_trgm_ops) WHERE key_id IS NULL;
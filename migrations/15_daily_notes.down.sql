@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notes_user_daily_date;
+
+ALTER TABLE notes DROP COLUMN IF EXISTS daily_date;
+
+ALTER TABLE users DROP COLUMN IF EXISTS daily_template;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
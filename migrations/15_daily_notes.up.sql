@@ -0,0 +1,9 @@
+-- user settings, NULL means the configured default
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS daily_template TEXT;
+
+-- calendar day of a journal note in the timezone of the user
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS daily_date DATE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notes_user_daily_date ON notes (user_id, daily_date)
+    WHERE daily_date IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS note_revisions;
+
+ALTER TABLE users DROP COLUMN IF EXISTS autocorrect_exclude;
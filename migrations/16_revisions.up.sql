@@ -0,0 +1,15 @@
+-- words autocorrect leaves as they are
+ALTER TABLE users ADD COLUMN IF NOT EXISTS autocorrect_exclude TEXT[] NOT NULL DEFAULT '{}';
+
+-- earlier texts of notes, e.g. the text before autocorrect, encrypted like notes
+CREATE TABLE IF NOT EXISTS note_revisions (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    key_id INTEGER REFERENCES data_keys(id),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_revisions_note_id ON note_revisions (note_id, id);
@@ -0,0 +1,8 @@
+-- personal dictionary, words are never reported as misspelled in any case
+CREATE TABLE IF NOT EXISTS dictionary_words (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    word TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dictionary_words_user_word ON dictionary_words (user_id, lower(word));
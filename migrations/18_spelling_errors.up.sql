@@ -0,0 +1,18 @@
+-- spelling errors of the current text of a note (revision_id NULL) and of its revisions,
+-- words and suggestions are encrypted like notes
+CREATE TABLE IF NOT EXISTS note_spelling_errors (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revision_id INTEGER REFERENCES note_revisions(id) ON DELETE CASCADE,
+    code INTEGER NOT NULL,
+    pos INTEGER NOT NULL,
+    word TEXT NOT NULL,
+    suggestions TEXT NOT NULL,
+    key_id INTEGER REFERENCES data_keys(id),
+    dismissed BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_spelling_errors_note_id ON note_spelling_errors (note_id, revision_id);
+CREATE INDEX IF NOT EXISTS idx_note_spelling_errors_open ON note_spelling_errors (note_id)
+    WHERE revision_id IS NULL AND NOT dismissed;
@@ -0,0 +1,17 @@
+-- queue of background spellchecks, failed jobs are retried until they are dead-lettered
+CREATE TABLE IF NOT EXISTS spellcheck_jobs (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- a note waits for a single check of its latest text
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spellcheck_jobs_pending_note ON spellcheck_jobs (note_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_spellcheck_jobs_run_at ON spellcheck_jobs (run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_spellcheck_jobs_note_id ON spellcheck_jobs (note_id, id);
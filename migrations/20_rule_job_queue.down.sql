@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_rule_jobs_active;
+
+ALTER TABLE rule_jobs DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE rule_jobs DROP COLUMN IF EXISTS last_note_id;
@@ -0,0 +1,5 @@
+-- rule jobs are run by workers page by page, a job whose lease expired continues after its last note
+ALTER TABLE rule_jobs ADD COLUMN IF NOT EXISTS last_note_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE rule_jobs ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_rule_jobs_active ON rule_jobs (id) WHERE status IN ('pending', 'running');
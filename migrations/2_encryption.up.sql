@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS data_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    master_key_version INTEGER NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS key_id INTEGER REFERENCES data_keys(id);
+
+CREATE INDEX IF NOT EXISTS idx_data_keys_user_id ON data_keys (user_id);
+CREATE INDEX IF NOT EXISTS idx_data_keys_master_key_version ON data_keys (master_key_version);
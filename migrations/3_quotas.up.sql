@@ -0,0 +1,7 @@
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS size_bytes INTEGER NOT NULL DEFAULT 0;
+
+-- encrypted notes are base64 of nonce (12 bytes) + ciphertext + tag (16 bytes)
+UPDATE notes SET size_bytes = CASE
+    WHEN key_id IS NULL THEN octet_length(note)
+    ELSE octet_length(decode(note, 'base64')) - 28
+END;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notes_user_id_created_at;
+ALTER TABLE notes DROP COLUMN IF EXISTS word_count;
+ALTER TABLE notes DROP COLUMN IF EXISTS char_count;
@@ -0,0 +1,10 @@
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS char_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS word_count INTEGER NOT NULL DEFAULT 0;
+
+-- encrypted notes are counted by rekey --reencrypt-notes
+UPDATE notes SET
+    char_count = char_length(note),
+    word_count = COALESCE(array_length(regexp_split_to_array(btrim(note), '\s+'), 1), 0) * (btrim(note) <> '')::int
+WHERE key_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notes_user_id_created_at ON notes (user_id, created_at);
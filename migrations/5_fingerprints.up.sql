@@ -0,0 +1 @@
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS fingerprint BIGINT;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS rule_jobs;
+DROP TABLE IF EXISTS rules;
+
+DROP INDEX IF EXISTS idx_notes_tags;
+ALTER TABLE notes DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE notes DROP COLUMN IF EXISTS source;
+ALTER TABLE notes DROP COLUMN IF EXISTS pinned;
+ALTER TABLE notes DROP COLUMN IF EXISTS notebook;
+ALTER TABLE notes DROP COLUMN IF EXISTS tags;
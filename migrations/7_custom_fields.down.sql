@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notes_fields;
+DROP TABLE IF EXISTS field_definitions;
+ALTER TABLE notes DROP COLUMN IF EXISTS fields;
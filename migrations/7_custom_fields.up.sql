@@ -0,0 +1,15 @@
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS field_definitions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    enum_values TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+-- containment filters (fields @> '{"status": "open"}')
+CREATE INDEX IF NOT EXISTS idx_notes_fields ON notes USING GIN (fields jsonb_path_ops);
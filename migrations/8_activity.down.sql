@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS note_activity;
+DROP FUNCTION IF EXISTS note_activity_append_only();
+ALTER TABLE notes DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,25 @@
+ALTER TABLE notes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- no foreign keys: the trail must outlive notes and users for compliance review
+CREATE TABLE IF NOT EXISTS note_activity (
+    id BIGSERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL,
+    owner_id INTEGER NOT NULL,
+    actor_id INTEGER,
+    action TEXT NOT NULL,
+    client_ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE OR REPLACE FUNCTION note_activity_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'note_activity is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS note_activity_append_only ON note_activity;
+CREATE TRIGGER note_activity_append_only BEFORE UPDATE OR DELETE ON note_activity
+    FOR EACH ROW EXECUTE FUNCTION note_activity_append_only();
+
+CREATE INDEX IF NOT EXISTS idx_note_activity_note_id ON note_activity (note_id, created_at);
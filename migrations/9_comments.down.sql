@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS note_comments;
+DROP TABLE IF EXISTS note_shares;
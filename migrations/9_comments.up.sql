@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS note_shares (
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (note_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_shares_user_id ON note_shares (user_id);
+
+-- body and anchor_text are sealed with the data key of the note owner
+CREATE TABLE IF NOT EXISTS note_comments (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    parent_id INTEGER REFERENCES note_comments(id) ON DELETE CASCADE,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    key_id INTEGER REFERENCES data_keys(id),
+    anchor_start INTEGER,
+    anchor_end INTEGER,
+    anchor_text TEXT,
+    anchor_detached BOOLEAN NOT NULL DEFAULT FALSE,
+    resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_comments_note_id ON note_comments (note_id, id);
@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 )
@@ -17,10 +18,15 @@ type Server struct {
 	server          *http.Server
 	notify          chan error
 	shutdownTimeout time.Duration
+	// cancel cancels contexts of requests still running after the shutdown timeout
+	cancel context.CancelFunc
 }
 
 func NewServer(handler http.Handler, configs ...Config) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	httpServer := &http.Server{
+		BaseContext:  func(net.Listener) context.Context { return ctx },
 		Handler:      handler,
 		ReadTimeout:  defaultReadTimeout,
 		WriteTimeout: defaultWriteTimeout,
@@ -31,6 +37,7 @@ func NewServer(handler http.Handler, configs ...Config) *Server {
 		server:          httpServer,
 		notify:          make(chan error, 1),
 		shutdownTimeout: defaultShutdownTimeout,
+		cancel:          cancel,
 	}
 
 	for _, config := range configs {
@@ -54,6 +61,7 @@ func (s *Server) Notify() <-chan error {
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
+	defer s.cancel()
 
 	return s.server.Shutdown(ctx)
 }